@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/server"
+	"github.com/bkarpinos/golink/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// Available command
+var availableCmd = &cobra.Command{
+	Use:   "available [alias]",
+	Short: "Check whether an alias is free to add",
+	Long: `Check whether add would accept an alias. Prints "available" and exits 0
+if it would; otherwise prints why not and exits non-zero, with the current
+target when the alias is taken. The alias is normalized the same way add
+does it.
+
+  golink available meeting && golink add meeting https://meet.example.com/abc`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := normalizeAlias(args[0])
+		if err := link.ValidateAlias(alias); err != nil {
+			return err
+		}
+
+		if l, err := store.Get(alias); err == nil {
+			return fmt.Errorf("%w: %s -> %s", storage.ErrExists, alias, strings.Join(l.URLs(), ", "))
+		}
+
+		// add accepts these, but the server answers them itself
+		if server.IsReserved(alias) {
+			fmt.Printf("available, but %s is reserved by the server and go/%s won't reach it\n", alias, alias)
+			return nil
+		}
+
+		fmt.Println("available")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(availableCmd)
+}
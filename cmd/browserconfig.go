@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Browser config command
+var browserConfigCmd = &cobra.Command{
+	Use:   "browser-config",
+	Short: "Generate browser settings for using go links from the address bar",
+	Long: `Print the settings for a custom search keyword, so typing "go meeting"
+in the address bar opens go/meeting without a go/ DNS entry.
+
+Chrome and Edge have no import for search engines, so instructions are
+printed for them. Firefox can import the keyword as a bookmark: use
+--bookmarks to write a bookmarks file, then import it from Bookmarks >
+Manage Bookmarks > Import and Backup > Import Bookmarks from HTML.
+
+With --direct, the bookmarks file has one bookmark per link, using the
+alias as its keyword and the link's final URL, so links work without a
+running server. Typing "gh" then opens the gh link, and a link with a
+single {name} placeholder takes it as an argument ("jira ABC-123").`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		browser, _ := cmd.Flags().GetString("browser")
+		serverURL, _ := cmd.Flags().GetString("server")
+		keyword, _ := cmd.Flags().GetString("keyword")
+		bookmarks, _ := cmd.Flags().GetBool("bookmarks")
+		direct, _ := cmd.Flags().GetBool("direct")
+
+		searchURL := strings.TrimRight(serverURL, "/") + "/%s"
+
+		if direct {
+			writeDirectBookmarks()
+			return nil
+		}
+		if bookmarks {
+			writeBookmarks([]bookmark{{Title: "Go links", URL: searchURL, Keyword: keyword}})
+			return nil
+		}
+
+		switch browser {
+		case "chrome":
+			printChromeConfig(keyword, searchURL)
+		case "firefox":
+			printFirefoxConfig(keyword, searchURL)
+		case "all":
+			printChromeConfig(keyword, searchURL)
+			fmt.Println()
+			printFirefoxConfig(keyword, searchURL)
+		default:
+			return usageError("unsupported browser %q (use chrome, firefox or all)", browser)
+		}
+		return nil
+	},
+}
+
+// bookmark is an entry in a generated bookmarks file
+type bookmark struct {
+	Title   string
+	URL     string
+	Keyword string
+}
+
+// printChromeConfig prints how to add the search keyword in Chrome and
+// other Chromium browsers
+func printChromeConfig(keyword, searchURL string) {
+	fmt.Println("Chrome, Edge and other Chromium browsers:")
+	fmt.Println("  1. Open chrome://settings/searchEngines (edge://settings/searchEngines in Edge)")
+	fmt.Println("  2. Next to \"Site search\", click Add")
+	fmt.Println("  3. Fill in:")
+	fmt.Printf("%7sName:     Go links\n", "")
+	fmt.Printf("%7sShortcut: %s\n", "", keyword)
+	fmt.Printf("%7sURL:      %s\n", "", searchURL)
+	fmt.Printf("Then type \"%s gh\" in the address bar.\n", keyword)
+}
+
+// printFirefoxConfig prints how to add the search keyword in Firefox
+func printFirefoxConfig(keyword, searchURL string) {
+	fmt.Println("Firefox:")
+	fmt.Println("  1. Create a bookmark (Bookmarks > Manage Bookmarks > Add Bookmark)")
+	fmt.Println("  2. Fill in:")
+	fmt.Printf("%7sName:    Go links\n", "")
+	fmt.Printf("%7sURL:     %s\n", "", searchURL)
+	fmt.Printf("%7sKeyword: %s\n", "", keyword)
+	fmt.Println("  Or import it: golink browser-config --bookmarks > golinks.html")
+	fmt.Printf("Then type \"%s gh\" in the address bar.\n", keyword)
+}
+
+// writeDirectBookmarks writes a bookmark for each link, keyed by its alias.
+// Links whose final URL isn't a web URL or has more than one placeholder
+// can't be bookmarked and are skipped.
+func writeDirectBookmarks() {
+	links := store.List()
+	sort.Slice(links, func(i, j int) bool { return links[i].Alias < links[j].Alias })
+
+	var entries []bookmark
+	for _, l := range links {
+		if l.Pattern != "" {
+			fmt.Fprintf(os.Stderr, "Skipping %s: pattern link\n", l.Alias)
+			continue
+		}
+		final, raw, err := followAliases(l)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping: %v\n", err)
+			continue
+		}
+		if !final.IsWeb() {
+			fmt.Fprintf(os.Stderr, "Skipping %s: not a web URL\n", l.Alias)
+			continue
+		}
+
+		// Browsers fill a single %s with whatever follows the keyword
+		switch params := final.Params(); len(params) {
+		case 0:
+		case 1:
+			raw = strings.ReplaceAll(raw, "{"+params[0]+"}", "%s")
+		default:
+			fmt.Fprintf(os.Stderr, "Skipping %s: more than one placeholder\n", l.Alias)
+			continue
+		}
+
+		title := l.Alias
+		if l.Description != "" {
+			title = l.Description
+		}
+		entries = append(entries, bookmark{Title: title, URL: raw, Keyword: l.Alias})
+	}
+
+	writeBookmarks(entries)
+}
+
+// writeBookmarks writes a Netscape bookmarks file, the format browsers
+// import and export, with each entry's keyword set
+func writeBookmarks(entries []bookmark) {
+	fmt.Println("<!DOCTYPE NETSCAPE-Bookmark-file-1>")
+	fmt.Println(`<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">`)
+	fmt.Println("<TITLE>Bookmarks</TITLE>")
+	fmt.Println("<H1>Bookmarks</H1>")
+	fmt.Println("<DL><p>")
+	fmt.Println("    <DT><H3>Go links</H3>")
+	fmt.Println("    <DL><p>")
+	for _, b := range entries {
+		fmt.Printf("        <DT><A HREF=\"%s\" SHORTCUTURL=\"%s\">%s</A>\n",
+			html.EscapeString(b.URL), html.EscapeString(b.Keyword), html.EscapeString(b.Title))
+	}
+	fmt.Println("    </DL><p>")
+	fmt.Println("</DL><p>")
+}
+
+func init() {
+	browserConfigCmd.Flags().String("browser", "all", "Browser to print settings for (chrome, firefox or all)")
+	browserConfigCmd.Flags().String("server", defaultServerURL, "URL of the golink server the keyword should use")
+	browserConfigCmd.Flags().String("keyword", "go", "Keyword to type before an alias in the address bar")
+	browserConfigCmd.Flags().Bool("bookmarks", false, "Write an importable bookmarks file with the keyword instead of instructions")
+	browserConfigCmd.Flags().Bool("direct", false, "Write an importable bookmarks file with a keyword for every link, without the server")
+
+	rootCmd.AddCommand(browserConfigCmd)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+
+	"github.com/spf13/cobra"
+)
+
+// categoryRule maps an alias, or a regular expression over aliases, to a category
+type categoryRule struct {
+	alias    string
+	pattern  *regexp.Regexp
+	category string
+}
+
+// Categorize command
+var categorizeCmd = &cobra.Command{
+	Use:   "categorize",
+	Short: "Assign categories to links in bulk",
+	Long: `Assign categories to many links at once from a CSV mapping file.
+
+Each row of the mapping file has two columns: an alias and a category.
+An alias wrapped in slashes (e.g. /^eng-/) is treated as a regular
+expression. Exact aliases take precedence over patterns, and patterns
+are tried in file order. Lines starting with # are ignored.`,
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		defaultCategory, _ := cmd.Flags().GetString("default")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if from == "" && defaultCategory == "" {
+			return usageError("at least one of --from or --default is required")
+		}
+
+		var rules []categoryRule
+		if from != "" {
+			f, err := os.Open(from)
+			if err != nil {
+				return err
+			}
+			rules, err = parseCategoryRules(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", from, err)
+			}
+		}
+
+		links := store.List()
+		sort.Slice(links, func(i, j int) bool {
+			return links[i].Alias < links[j].Alias
+		})
+
+		var changes []*link.Link
+		counts := make(map[string]int)
+		for _, l := range links {
+			category, ok := matchCategory(rules, l.Alias)
+			if !ok {
+				if l.Category != "" || defaultCategory == "" {
+					continue
+				}
+				category = defaultCategory
+			}
+			if category == l.Category {
+				continue
+			}
+
+			// Work on a copy so a dry run never touches the stored link
+			updated := *l
+			updated.Category = category
+			changes = append(changes, &updated)
+			counts[category]++
+
+			if dryRun {
+				fmt.Printf("%-15s %q -> %q\n", l.Alias, l.Category, category)
+			}
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("No links to update.")
+			return nil
+		}
+
+		if !dryRun {
+			if err := store.UpdateMany(changes); err != nil {
+				return err
+			}
+		}
+
+		categories := make([]string, 0, len(counts))
+		for category := range counts {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		if dryRun {
+			fmt.Printf("\nWould update %d links:\n", len(changes))
+		} else {
+			fmt.Printf("Updated %d links:\n", len(changes))
+		}
+		for _, category := range categories {
+			fmt.Printf("  %s: %d\n", category, counts[category])
+		}
+		return nil
+	},
+}
+
+// parseCategoryRules reads alias/pattern to category rules from CSV
+func parseCategoryRules(r io.Reader) ([]categoryRule, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var rules []categoryRule
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		key := strings.TrimSpace(record[0])
+		category := strings.TrimSpace(record[1])
+		if key == "" || category == "" {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: alias and category must not be empty", line)
+		}
+
+		rule := categoryRule{category: category}
+		if len(key) > 2 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") {
+			pattern, err := regexp.Compile(key[1 : len(key)-1])
+			if err != nil {
+				line, _ := reader.FieldPos(0)
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			rule.pattern = pattern
+		} else {
+			rule.alias = key
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// matchCategory returns the category for an alias, preferring exact matches over patterns
+func matchCategory(rules []categoryRule, alias string) (string, bool) {
+	for _, rule := range rules {
+		if rule.pattern == nil && rule.alias == alias {
+			return rule.category, true
+		}
+	}
+	for _, rule := range rules {
+		if rule.pattern != nil && rule.pattern.MatchString(alias) {
+			return rule.category, true
+		}
+	}
+	return "", false
+}
+
+func init() {
+	categorizeCmd.Flags().String("from", "", "CSV file mapping aliases (or /regex/) to categories")
+	categorizeCmd.Flags().String("default", "", "Category to assign to uncategorized links that match no rule")
+	categorizeCmd.Flags().Bool("dry-run", false, "Show what would change without saving")
+	categorizeCmd.RegisterFlagCompletionFunc("default", completeCategories)
+
+	rootCmd.AddCommand(categorizeCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Category command
+var categoryCmd = &cobra.Command{
+	Use:   "category",
+	Short: "Manage how categories are displayed",
+}
+
+// Category set command
+var categorySetCmd = &cobra.Command{
+	Use:   "set [category]",
+	Short: "Set the homepage color and icon for a category",
+	Long: `Set the color and icon shown next to a category on the homepage. The
+style is stored in the config file, not on the links. Pass an empty value
+to clear a setting.
+
+  golink category set eng --color '#1f6feb' --icon 🛠️`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCategoryNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if strings.Contains(name, ".") {
+			return usageError("category names with '.' can't be styled")
+		}
+
+		styles := categoryStyles()
+		style := styles[name]
+		if cmd.Flags().Changed("color") {
+			style.Color, _ = cmd.Flags().GetString("color")
+		}
+		if cmd.Flags().Changed("icon") {
+			style.Icon, _ = cmd.Flags().GetString("icon")
+		}
+		if err := style.Validate(); err != nil {
+			return err
+		}
+
+		if style == (server.CategoryStyle{}) {
+			delete(styles, name)
+		} else {
+			styles[name] = style
+		}
+
+		// Store plain maps so the config file keeps lowercase keys
+		config := make(map[string]any, len(styles))
+		for n, st := range styles {
+			config[n] = map[string]string{"color": st.Color, "icon": st.Icon}
+		}
+		viper.Set("categories", config)
+
+		if err := writeConfig(); err != nil {
+			return fmt.Errorf("writing config: %w", err)
+		}
+		fmt.Printf("Updated category %s. Restart the server for changes to take effect.\n", name)
+		return nil
+	},
+}
+
+// Category list command
+var categoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List categories with a configured style",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		styles := categoryStyles()
+		if len(styles) == 0 {
+			fmt.Println("No category styles configured.")
+			return nil
+		}
+
+		names := make([]string, 0, len(styles))
+		for name := range styles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("%-15s color: %-10s icon: %s\n", name, styles[name].Color, styles[name].Icon)
+		}
+		return nil
+	},
+}
+
+// categoryStyles returns the configured category styles keyed by lowercased
+// category name
+func categoryStyles() map[string]server.CategoryStyle {
+	styles := make(map[string]server.CategoryStyle)
+	if err := viper.UnmarshalKey("categories", &styles); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid categories config: %v\n", err)
+		return make(map[string]server.CategoryStyle)
+	}
+	return styles
+}
+
+// completeCategoryNames completes the first argument with existing categories
+func completeCategoryNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeCategories(cmd, args, toComplete)
+}
+
+func init() {
+	categorySetCmd.Flags().String("color", "", "CSS color for the category (#rgb, #rrggbb or a color name)")
+	categorySetCmd.Flags().String("icon", "", "Emoji or short text shown before the category name")
+
+	categoryCmd.AddCommand(categorySetCmd)
+	categoryCmd.AddCommand(categoryListCmd)
+	rootCmd.AddCommand(categoryCmd)
+}
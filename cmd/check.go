@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/server"
+	"github.com/bkarpinos/golink/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// Check command
+var checkCmd = &cobra.Command{
+	Use:   "check [file]",
+	Short: "Validate a links file without opening the store",
+	Long: `Validate every link in a links file and list any problems: URLs that
+don't parse or use a scheme missing from link_schemes, illegal or
+reserved aliases, aliases that appear more than once, and alias: references
+to links that don't exist or that loop. The file is only
+read, never written or watched, so this is safe to run in CI or a
+pre-commit hook.
+
+With no file, the configured links.json is checked. Exits non-zero when any
+problem is found.`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{skipStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := filepath.Join(storageDir, "links.json")
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		entries, err := storage.ReadEntries(path)
+		if err != nil {
+			return err
+		}
+
+		problems := checkEntries(entries)
+		for _, p := range problems {
+			fmt.Printf("%s: %s\n", path, p)
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("%d problems in %d links", len(problems), len(entries))
+		}
+		fmt.Printf("%s: %d links OK\n", path, len(entries))
+		return nil
+	},
+}
+
+// checkEntries returns a description of each problem found in a links file
+func checkEntries(entries []storage.Entry) []string {
+	linkSchemes := configList("link_schemes")
+
+	var problems []string
+	seen := make(map[string]bool, len(entries))
+
+	links := make(map[string]*link.Link, len(entries))
+	for _, e := range entries {
+		links[e.Key] = e.Link
+	}
+	lookup := func(alias string) (*link.Link, bool) {
+		l, ok := links[alias]
+		return l, ok
+	}
+
+	for _, e := range entries {
+		if seen[e.Key] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate alias", e.Key))
+		}
+		seen[e.Key] = true
+
+		if e.Link.Alias != e.Key {
+			problems = append(problems, fmt.Sprintf("%s: alias field is %q", e.Key, e.Link.Alias))
+		}
+		if err := link.ValidateAlias(e.Key); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", e.Key, err))
+		} else if server.IsReserved(e.Key) {
+			problems = append(problems, fmt.Sprintf("%s: alias is reserved by the server", e.Key))
+		}
+		if err := e.Link.ValidateURL(linkSchemes); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", e.Key, err))
+		}
+		if e.Link.Pattern != "" {
+			if err := e.Link.ValidatePattern(); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: pattern: %v", e.Key, err))
+			}
+		}
+
+		for _, target := range e.Link.URLs() {
+			if ref, ok := link.AliasRef(target); ok && links[ref] == nil {
+				problems = append(problems, fmt.Sprintf("%s: refers to missing alias %s", e.Key, ref))
+			}
+		}
+		if _, _, err := link.FollowAliases(e.Link, firstTarget, lookup); errors.Is(err, link.ErrAliasLoop) {
+			problems = append(problems, fmt.Sprintf("%s: %v", e.Key, link.ErrAliasLoop))
+		}
+	}
+	return problems
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+}
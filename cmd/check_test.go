@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+	"github.com/spf13/viper"
+)
+
+func TestCheckEntries(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	// pattern returns a pattern link
+	pattern := func(alias, re, url string) *link.Link {
+		l := link.NewLink(alias, url, "", "")
+		l.Pattern = re
+		return l
+	}
+
+	tests := []struct {
+		name string
+		link *link.Link
+		want []string
+	}{
+		{"valid link", link.NewLink("docs", "https://docs.example.com", "", ""), nil},
+		{"valid pattern", pattern("bugs", `^bug/(\d+)$`, "https://tracker.example.com/$1"), nil},
+		{"reserved alias", link.NewLink("healthz", "https://example.com", "", ""), []string{"healthz: alias is reserved by the server"}},
+		{"missing alias", link.NewLink("a", "alias:nowhere", "", ""), []string{"a: refers to missing alias nowhere"}},
+		{
+			"pattern doesn't compile",
+			pattern("bugs", `^bug/(\d+$`, "https://tracker.example.com/$1"),
+			[]string{"bugs: pattern: invalid pattern \"^bug/(\\\\d+$\": error parsing regexp: missing closing ): `^bug/(\\d+$`"},
+		},
+		{
+			"pattern target refers to a missing group",
+			pattern("bugs", `^bug/(\d+)$`, "https://tracker.example.com/$2"),
+			[]string{"bugs: pattern: url refers to $2, which the pattern doesn't capture"},
+		},
+	}
+	for _, tt := range tests {
+		got := checkEntries([]storage.Entry{{Key: tt.link.Alias, Link: tt.link}})
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: problems %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultServerURL is where commands look for a running golink server
+const defaultServerURL = "http://localhost"
+
+// httpClient is used for talking to a running golink server
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// getJSON fetches path from a running golink server and decodes the JSON response into v
+func getJSON(serverURL, path string, v any) error {
+	resp, err := httpClient.Get(strings.TrimSuffix(serverURL, "/") + path)
+	if err != nil {
+		return fmt.Errorf("could not reach server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// postJSON sends an empty POST to a running golink server, authenticating
+// with token if set, and decodes the JSON response into v
+func postJSON(serverURL, path, token string, v any) error {
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(serverURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach server: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
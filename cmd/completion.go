@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// loadIndex returns the completion index for the links file
+func loadIndex() (*storage.Index, error) {
+	return storage.LoadIndex(filepath.Join(storageDir, "links.json"), filepath.Join(storageDir, ".links.idx"))
+}
+
+// completeAliases completes the first argument with existing aliases
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	idx, err := loadIndex()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(idx.Aliases, normalizeAlias(toComplete)), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCategories completes a flag value with existing categories
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	idx, err := loadIndex()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(idx.Categories, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterPrefix returns the values starting with prefix
+func filterPrefix(values []string, prefix string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/platform"
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configKinds lists the type of each scalar config key, so values from the
+// config file or environment can be checked before a command relies on them
+var configKinds = map[string]string{
+	"port":                "port",
+	"rpc_port":            "int",
+	"monitor_concurrency": "int",
+	"log_max_size":        "int",
+	"log_backups":         "int",
+	"log_sample":          "fraction",
+	"strict_params":       "bool",
+	"proxy_autoconfig":    "bool",
+	"favicons":            "bool",
+	"storage_pretty":      "bool",
+	"events_metadata":     "bool",
+	"block_private":       "bool",
+	"idle_count_health":   "bool",
+	"idle_shutdown":       "duration",
+	"monitor_interval":    "duration",
+	"monitor_timeout":     "duration",
+	"log_max_age":         "duration",
+	"not_found":           "url",
+	"events_url":          "url",
+	"beacon_url":          "url",
+	"timezone":            "timezone",
+}
+
+// schemePattern matches a URL scheme name as used in link_schemes
+var schemePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+// validateConfig checks the loaded configuration and returns a description
+// of each problem. Only settings that are actually set are checked; command
+// flags are validated by their commands. The storage directory is only
+// checked for being writable if writes is set.
+func validateConfig(writes bool) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, 0, len(configKinds))
+	for key := range configKinds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !viper.IsSet(key) {
+			continue
+		}
+		if err := checkConfigValue(configKinds[key], viper.Get(key)); err != nil {
+			add("%s: %v", key, err)
+		}
+	}
+
+	if writes {
+		if err := checkWritableDir(storageDir); err != nil {
+			add("storage_dir: %v", err)
+		}
+	}
+
+	if viper.IsSet("alias_space") {
+		if space := viper.GetString("alias_space"); space != "" && link.ValidateAlias("a"+space+"a") != nil {
+			add("alias_space: %q can't be used in aliases", space)
+		}
+	}
+	if viper.IsSet("redirect_mode") {
+		if err := link.ValidateRedirect(viper.GetString("redirect_mode")); err != nil {
+			add("redirect_mode: %v", err)
+		}
+	}
+
+	if viper.IsSet("browser") {
+		if err := platform.ValidateBrowser(viper.GetString("browser")); err != nil {
+			add("browser: %v", err)
+		}
+	}
+	if viper.IsSet("log_level") {
+		if err := server.ValidateLogLevel(viper.GetString("log_level")); err != nil {
+			add("log_level: %v", err)
+		}
+	}
+
+	for _, key := range []string{"link_schemes", "redirect_schemes"} {
+		for _, scheme := range configList(key) {
+			if !schemePattern.MatchString(scheme) {
+				add("%s: %q is not a URL scheme", key, scheme)
+			}
+		}
+	}
+	if _, err := server.ParseHostAllowlist(configList("allowed_redirect_hosts")); err != nil {
+		add("allowed_redirect_hosts: %v", err)
+	}
+	for _, key := range []string{"trusted_proxies", "allow_private"} {
+		if _, err := server.ParseNetworks(configList(key)); err != nil {
+			add("%s: %v", key, err)
+		}
+	}
+
+	for name, style := range categoryStyles() {
+		if err := style.Validate(); err != nil {
+			add("categories.%s: %v", name, err)
+		}
+	}
+
+	return problems
+}
+
+// checkConfigValue checks that a config value can be read as kind
+func checkConfigValue(kind string, value any) error {
+	switch kind {
+	case "bool":
+		_, err := cast.ToBoolE(value)
+		return err
+	case "int":
+		_, err := cast.ToIntE(value)
+		return err
+	case "port":
+		port, err := cast.ToIntE(value)
+		if err != nil {
+			return err
+		}
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("port %d is out of range (1-65535)", port)
+		}
+	case "fraction":
+		f, err := cast.ToFloat64E(value)
+		if err != nil {
+			return err
+		}
+		if f < 0 || f > 1 {
+			return fmt.Errorf("%v must be between 0 and 1", f)
+		}
+	case "duration":
+		_, err := cast.ToDurationE(value)
+		return err
+	case "timezone":
+		_, err := time.LoadLocation(cast.ToString(value))
+		return err
+	case "url":
+		raw := cast.ToString(value)
+		if raw == "" {
+			return nil
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%q is not an http(s) URL", raw)
+		}
+	}
+	return nil
+}
+
+// checkWritableDir checks that links can be written to dir
+func checkWritableDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("%q is not an absolute path", dir)
+	}
+	f, err := os.CreateTemp(dir, ".golink-write-check-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %v", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// checkConfig reports configuration problems before a command runs. They
+// are warnings unless --strict-config is set, in which case an error is
+// returned and the command doesn't run.
+func checkConfig(cmd *cobra.Command) error {
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(renamedKeys)) {
+		if old := renamedKeys[key]; viper.IsSet(old) {
+			fmt.Fprintf(os.Stderr, "Warning: config %s is deprecated, use %s\n", old, key)
+		}
+	}
+
+	problems := validateConfig(cmd.Annotations[writesStoreAnnotation] != "")
+	if len(problems) == 0 {
+		return nil
+	}
+
+	strict, _ := cmd.Flags().GetBool("strict-config")
+	label := "Warning"
+	if strict {
+		label = "Error"
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s: config %s\n", label, p)
+	}
+	if strict {
+		where := "the GOLINK_* environment variables"
+		if file := viper.ConfigFileUsed(); file != "" {
+			where = file + " or " + where
+		}
+		return fmt.Errorf("fix the settings in %s, or run without --strict-config", where)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidateConfigWritableDir(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	oldStorageDir := storageDir
+	t.Cleanup(func() { storageDir = oldStorageDir })
+
+	// A directory that can't be written to (root can write to read-only
+	// ones, so use one that doesn't exist)
+	storageDir = filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name     string
+		writes   bool
+		problems bool
+	}{
+		{"list", false, false},
+		{"open", false, false},
+		{"add", true, true},
+		{"serve", true, true},
+	}
+	for _, tt := range tests {
+		cmd, _, err := rootCmd.Find([]string{tt.name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		writes := cmd.Annotations[writesStoreAnnotation] != ""
+		if writes != tt.writes {
+			t.Errorf("%s: marked as writing %v, want %v", tt.name, writes, tt.writes)
+		}
+		problems := validateConfig(writes)
+		found := slices.ContainsFunc(problems, func(p string) bool { return strings.HasPrefix(p, "storage_dir:") })
+		if found != tt.problems {
+			t.Errorf("%s: problems %q, want a storage_dir problem %v", tt.name, problems, tt.problems)
+		}
+	}
+}
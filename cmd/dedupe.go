@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+
+	"github.com/spf13/cobra"
+)
+
+// Dedupe command
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge links that point at the same URL",
+	Long: `Find links whose URLs are the same after normalization (case of the
+scheme and host, default ports and trailing slashes are ignored) and
+merge each group into one link. Links to different anchors on the same
+page are kept apart.
+
+The kept link takes the description and category of a removed duplicate
+when it has none of its own. Choose which link to keep with --keep, or
+pick interactively for each group.`,
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetString("keep")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if keep != "" && keep != "oldest" && keep != "newest" {
+			return usageError("unsupported --keep value %q (use oldest or newest)", keep)
+		}
+
+		groups := duplicateGroups(store.List())
+		if len(groups) == 0 {
+			fmt.Println("No duplicate links found.")
+			return nil
+		}
+
+		input := bufio.NewReader(os.Stdin)
+		var updates []*link.Link
+		var removed []string
+
+		for _, group := range groups {
+			fmt.Printf("%s\n", group[0].URL)
+			for i, l := range group {
+				fmt.Printf("  %d) %-15s created %s\n", i+1, l.Alias, l.CreatedAt.Format("2006-01-02"))
+			}
+
+			var primary int
+			switch keep {
+			case "oldest":
+				primary = 0
+			case "newest":
+				primary = len(group) - 1
+			default:
+				choice, ok := promptChoice(input, len(group))
+				if !ok {
+					fmt.Println("  skipped")
+					continue
+				}
+				primary = choice
+			}
+
+			merged := *group[primary]
+			changed := false
+			for i, l := range group {
+				if i == primary {
+					continue
+				}
+				if merged.Description == "" && l.Description != "" {
+					merged.Description = l.Description
+					changed = true
+				}
+				if merged.Category == "" && l.Category != "" {
+					merged.Category = l.Category
+					changed = true
+				}
+				removed = append(removed, l.Alias)
+				fmt.Printf("  remove %s (keeping %s)\n", l.Alias, merged.Alias)
+			}
+			if changed {
+				updates = append(updates, &merged)
+			}
+		}
+
+		if len(removed) == 0 {
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("\nWould remove %d duplicate links.\n", len(removed))
+			return nil
+		}
+
+		if err := store.MergeMany(updates, removed); err != nil {
+			return err
+		}
+		fmt.Printf("\nRemoved %d duplicate links.\n", len(removed))
+		return nil
+	},
+}
+
+// duplicateGroups returns groups of two or more links with the same
+// normalized URL, each ordered oldest first
+func duplicateGroups(links []*link.Link) [][]*link.Link {
+	byURL := make(map[string][]*link.Link)
+	for _, l := range links {
+		key := normalizeURL(l.URL)
+		byURL[key] = append(byURL[key], l)
+	}
+
+	var groups [][]*link.Link
+	for _, group := range byURL {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].CreatedAt.Equal(group[j].CreatedAt) {
+				return group[i].CreatedAt.Before(group[j].CreatedAt)
+			}
+			return group[i].Alias < group[j].Alias
+		})
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0].Alias < groups[j][0].Alias
+	})
+	return groups
+}
+
+// normalizeURL returns a comparison key for a URL, ignoring differences that
+// don't change where it leads. The fragment is kept, since docs#install and
+// docs#faq are different links.
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host = host + ":" + port
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// promptChoice asks which of n links to keep, returning its index. An empty
+// answer keeps the first (oldest); "s" skips the group.
+func promptChoice(input *bufio.Reader, n int) (int, bool) {
+	for {
+		fmt.Printf("  Keep which? [1-%d, s to skip] (1): ", n)
+		answer, err := input.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if err != nil && answer == "" {
+			return 0, false
+		}
+
+		switch answer {
+		case "":
+			return 0, true
+		case "s", "S":
+			return 0, false
+		}
+
+		if choice, convErr := strconv.Atoi(answer); convErr == nil && choice >= 1 && choice <= n {
+			return choice - 1, true
+		}
+		fmt.Printf("  Please enter a number from 1 to %d, or s.\n", n)
+	}
+}
+
+func init() {
+	dedupeCmd.Flags().String("keep", "", "Which link to keep without prompting (oldest or newest)")
+	dedupeCmd.Flags().Bool("dry-run", false, "Show what would be merged without saving")
+
+	rootCmd.AddCommand(dedupeCmd)
+}
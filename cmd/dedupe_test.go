@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://example.com/docs", "https://example.com/docs/", true},
+		{"https://Example.COM/docs", "HTTPS://example.com/docs", true},
+		{"https://example.com:443/docs", "https://example.com/docs", true},
+		{"http://example.com:80/", "http://example.com", true},
+		{"https://example.com/docs?a=1", "https://example.com/docs/?a=1", true},
+
+		{"https://example.com/docs", "http://example.com/docs", false},
+		{"https://example.com:8443/docs", "https://example.com/docs", false},
+		{"https://example.com/Docs", "https://example.com/docs", false},
+		{"https://example.com/docs?a=1", "https://example.com/docs?a=2", false},
+		{"https://example.com/docs#install", "https://example.com/docs#faq", false},
+		{"https://example.com/docs#install", "https://example.com/docs", false},
+	}
+	for _, tt := range tests {
+		if same := normalizeURL(tt.a) == normalizeURL(tt.b); same != tt.same {
+			t.Errorf("normalizeURL(%q) == normalizeURL(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+		}
+	}
+}
+
+func TestDuplicateGroups(t *testing.T) {
+	day := func(d int, alias, url string) *link.Link {
+		l := link.NewLink(alias, url, "", "")
+		l.CreatedAt = time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+		return l
+	}
+	links := []*link.Link{
+		day(3, "docs", "https://example.com/docs/"),
+		day(1, "documentation", "https://EXAMPLE.com/docs"),
+		day(2, "d", "https://example.com:443/docs"),
+		day(1, "install", "https://example.com/docs#install"),
+		day(1, "setup", "https://example.com/docs#install"),
+		day(1, "unique", "https://example.org"),
+	}
+
+	var got [][]string
+	for _, group := range duplicateGroups(links) {
+		var aliases []string
+		for _, l := range group {
+			aliases = append(aliases, l.Alias)
+		}
+		got = append(got, aliases)
+	}
+
+	// Each group is ordered oldest first, and groups by their first alias
+	want := [][]string{
+		{"documentation", "d", "docs"},
+		{"install", "setup"},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("duplicateGroups = %q, want %q", got, want)
+	}
+}
+
+func TestDedupeMerge(t *testing.T) {
+	older := link.NewLink("documentation", "https://example.com/docs", "", "")
+	older.CreatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := link.NewLink("docs", "https://example.com/docs/", "Product docs", "eng")
+	newer.CreatedAt = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	useTestStore(t, older, newer, link.NewLink("other", "https://example.org", "", ""))
+
+	if err := dedupeCmd.Flags().Set("keep", "oldest"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dedupeCmd.Flags().Set("keep", "") })
+	if _, err := captureStdout(t, func() error { return dedupeCmd.RunE(dedupeCmd, nil) }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The oldest is kept, taking the description and category it lacked
+	kept, err := store.Get("documentation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Description != "Product docs" || kept.Category != "eng" {
+		t.Errorf("kept link has description %q and category %q, want those of the removed duplicate", kept.Description, kept.Category)
+	}
+	if _, err := store.Get("docs"); err == nil {
+		t.Error("duplicate docs wasn't removed")
+	}
+	if store.Count() != 2 {
+		t.Errorf("%d links after dedupe, want 2", store.Count())
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// fieldChange is a single field that differs between two versions of a link
+type fieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// linkChange lists the fields that differ for an alias present in both files
+type linkChange struct {
+	Alias   string        `json:"alias"`
+	Changes []fieldChange `json:"changes"`
+}
+
+// linksDiff is the difference between two links files
+type linksDiff struct {
+	Added    []*link.Link `json:"added"`
+	Removed  []*link.Link `json:"removed"`
+	Modified []linkChange `json:"modified"`
+}
+
+// Diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [old-file] [new-file]",
+	Short: "Show the differences between two links files",
+	Long: `Compare two links files and report added, removed and modified links,
+field by field. Useful for reviewing catalog changes kept in git.`,
+	Args:        cobra.ExactArgs(2),
+	Annotations: map[string]string{skipStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return usageError("unsupported format %q (use text or json)", format)
+		}
+
+		oldLinks, err := storage.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", args[0], err)
+		}
+		newLinks, err := storage.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", args[1], err)
+		}
+
+		d, err := diffLinks(oldLinks, newLinks)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.Encode(d)
+			return nil
+		}
+
+		if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 {
+			fmt.Println("No differences.")
+			return nil
+		}
+
+		for _, l := range d.Added {
+			fmt.Printf("+ %-15s -> %s\n", l.Alias, l.URL)
+		}
+		for _, l := range d.Removed {
+			fmt.Printf("- %-15s -> %s\n", l.Alias, l.URL)
+		}
+		for _, m := range d.Modified {
+			fmt.Printf("~ %s\n", m.Alias)
+			for _, c := range m.Changes {
+				oldValue, _ := json.Marshal(c.Old)
+				newValue, _ := json.Marshal(c.New)
+				fmt.Printf("%4s%s: %s -> %s\n", "", c.Field, oldValue, newValue)
+			}
+		}
+		fmt.Printf("\n%d added, %d removed, %d modified\n", len(d.Added), len(d.Removed), len(d.Modified))
+		return nil
+	},
+}
+
+// diffLinks compares two sets of links keyed by alias. Fields are compared by
+// their JSON form so every stored field is covered.
+func diffLinks(oldLinks, newLinks map[string]*link.Link) (*linksDiff, error) {
+	d := &linksDiff{
+		Added:    []*link.Link{},
+		Removed:  []*link.Link{},
+		Modified: []linkChange{},
+	}
+
+	for _, alias := range sortedAliases(newLinks) {
+		if _, ok := oldLinks[alias]; !ok {
+			d.Added = append(d.Added, newLinks[alias])
+		}
+	}
+
+	for _, alias := range sortedAliases(oldLinks) {
+		newLink, ok := newLinks[alias]
+		if !ok {
+			d.Removed = append(d.Removed, oldLinks[alias])
+			continue
+		}
+
+		oldFields, err := linkFields(oldLinks[alias])
+		if err != nil {
+			return nil, err
+		}
+		newFields, err := linkFields(newLink)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[string]bool)
+		for name := range oldFields {
+			names[name] = true
+		}
+		for name := range newFields {
+			names[name] = true
+		}
+
+		var changes []fieldChange
+		for _, name := range sortedKeys(names) {
+			if !reflect.DeepEqual(oldFields[name], newFields[name]) {
+				changes = append(changes, fieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+			}
+		}
+		if len(changes) > 0 {
+			d.Modified = append(d.Modified, linkChange{Alias: alias, Changes: changes})
+		}
+	}
+
+	return d, nil
+}
+
+// linkFields returns a link's fields as they appear in JSON
+func linkFields(l *link.Link) (map[string]any, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// sortedAliases returns the keys of a links map in sorted order
+func sortedAliases(links map[string]*link.Link) []string {
+	aliases := make([]string, 0, len(links))
+	for alias := range links {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	diffCmd.Flags().String("format", "text", "Output format (text or json)")
+
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+)
+
+func TestDiffIgnoresBrokenStore(t *testing.T) {
+	oldStorageDir, oldStore := storageDir, store
+	storageDir = t.TempDir()
+	t.Cleanup(func() { storageDir, store = oldStorageDir, oldStore })
+	if err := os.WriteFile(filepath.Join(storageDir, "links.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	if err := storage.WriteFile(a, []*link.Link{link.NewLink("docs", "https://docs.example.com", "", "")}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.WriteFile(b, []*link.Link{link.NewLink("docs", "https://docs.example.org", "", "")}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Commands that use the store report it as broken, but diff doesn't open it
+	if err := initStore(listCmd, nil); err == nil {
+		t.Fatal("opening the malformed store succeeded")
+	}
+	if err := initStore(diffCmd, []string{a, b}); err != nil {
+		t.Fatalf("diff opened the store: %v", err)
+	}
+	out, err := captureStdout(t, func() error { return diffCmd.RunE(diffCmd, []string{a, b}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "https://docs.example.org") {
+		t.Errorf("diff printed %q, want the changed URL", out)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// passphraseEnv names the environment variable holding the passphrase for an
+// encrypted links file. It is read from the environment only, never from the
+// config file, so the passphrase isn't stored next to the links it protects.
+const passphraseEnv = "GOLINK_PASSPHRASE"
+
+// Encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the links file at rest",
+	Long: `Rewrite links.json encrypted with a key derived from the passphrase in
+` + passphraseEnv + `. Every command, including serve, then needs the same
+passphrase to read or change links. Use decrypt to go back to plaintext.`,
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if os.Getenv(passphraseEnv) == "" {
+			return fmt.Errorf("set %s to the passphrase to encrypt with", passphraseEnv)
+		}
+		if store.Encrypted() {
+			fmt.Println("Links are already encrypted.")
+			return nil
+		}
+
+		if err := store.SetEncrypted(true); err != nil {
+			return err
+		}
+
+		// The completion index lists aliases in plaintext, so don't leave one behind
+		os.Remove(filepath.Join(storageDir, ".links.idx"))
+
+		fmt.Printf("Encrypted %s\n", store.Path())
+		return nil
+	},
+}
+
+// Decrypt command
+var decryptCmd = &cobra.Command{
+	Use:         "decrypt",
+	Short:       "Store the links file as plaintext again",
+	Long:        `Rewrite an encrypted links.json as plaintext JSON. The current passphrase must be set in ` + passphraseEnv + `.`,
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !store.Encrypted() {
+			fmt.Println("Links are not encrypted.")
+			return nil
+		}
+
+		if err := store.SetEncrypted(false); err != nil {
+			return err
+		}
+		fmt.Printf("Decrypted %s\n", store.Path())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(encryptCmd)
+	rootCmd.AddCommand(decryptCmd)
+}
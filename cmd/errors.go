@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+)
+
+// Exit codes. Commands return errors from RunE and Execute turns them into
+// one of these, so scripts can tell a missing link from a mistyped command.
+const (
+	exitFailure  = 1 // The command failed: storage, network or other errors
+	exitUsage    = 2 // Invalid arguments, flags or flag values
+	exitNotFound = 3 // The requested link doesn't exist
+)
+
+// usageErr marks an error as caused by how the command was invoked
+type usageErr struct {
+	err error
+}
+
+func (e usageErr) Error() string { return e.err.Error() }
+func (e usageErr) Unwrap() error { return e.err }
+
+// usageError returns a usage error, formatted like fmt.Errorf
+func usageError(format string, args ...any) error {
+	return usageErr{fmt.Errorf(format, args...)}
+}
+
+// commandStarted is set once a command's arguments and flags have been
+// parsed, so errors cobra returns before that are known to be usage errors
+var commandStarted bool
+
+// exitCode returns the process exit code for an error returned by a command
+func exitCode(err error) int {
+	var usage usageErr
+	switch {
+	case !commandStarted, errors.As(err, &usage):
+		return exitUsage
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, link.ErrMissingAlias):
+		return exitNotFound
+	}
+	return exitFailure
+}
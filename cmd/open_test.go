@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/platform"
+	"github.com/bkarpinos/golink/internal/storage"
+	"github.com/spf13/viper"
+)
+
+// useTestStore points the commands at a temporary store holding links
+func useTestStore(t *testing.T, links ...*link.Link) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "links.json")
+	if err := storage.WriteFile(path, links, true); err != nil {
+		t.Fatal(err)
+	}
+	s, err := storage.NewJSONStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := store
+	store = s
+	t.Cleanup(func() { store = old })
+}
+
+// useMockPlatform makes the commands record what they open instead of
+// running the system opener
+func useMockPlatform(t *testing.T) *platform.Mock {
+	t.Helper()
+	mock := &platform.Mock{}
+	detectPlatform = func() (platform.Platform, error) { return mock, nil }
+	t.Cleanup(func() { detectPlatform = platform.Detect })
+	return mock
+}
+
+func TestOpenFileLink(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	useTestStore(t,
+		link.NewLink("notes", "file:///home/me/notes.txt", "", ""),
+		link.NewLink("docs", "https://docs.example.com", "", ""),
+	)
+	mock := useMockPlatform(t)
+
+	// file:// targets go straight to the system opener, since browsers won't
+	// follow a redirect to them; web links go through go/
+	for _, alias := range []string{"notes", "docs"} {
+		if err := openCmd.RunE(openCmd, []string{alias}); err != nil {
+			t.Fatalf("open %s: %v", alias, err)
+		}
+	}
+	want := []string{"file:///home/me/notes.txt", "http://go/docs"}
+	if !slices.Equal(mock.Opened, want) {
+		t.Errorf("opened %q, want %q", mock.Opened, want)
+	}
+}
+
+func TestURLFromClipboard(t *testing.T) {
+	mock := useMockPlatform(t)
+
+	tests := []struct {
+		clipboard string
+		want      string
+		wantErr   bool
+	}{
+		{"https://example.com/page\n", "https://example.com/page", false},
+		{"  http://example.com  ", "http://example.com", false},
+		{"just some text", "", true},
+		{"file:///etc/passwd", "", true},
+		{"https://", "", true},
+	}
+	for _, tt := range tests {
+		mock.Clipboard = tt.clipboard
+		got, err := urlFromClipboard()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("urlFromClipboard with %q = %q, %v; want %q, error %v", tt.clipboard, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Reload command
+var reloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Make a running server reload links.json",
+	Long: `Ask a running golink server to re-read links.json immediately, for
+when the file was edited by hand and you don't want to wait for the file
+watcher. The token defaults to the admin_token setting; servers without
+one only accept reloads from the same machine.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverURL, _ := cmd.Flags().GetString("server")
+		token, _ := cmd.Flags().GetString("token")
+		if token == "" {
+			token = viper.GetString("admin_token")
+		}
+
+		var result struct {
+			Links int `json:"links"`
+		}
+		if err := postJSON(serverURL, "/api/reload", token, &result); err != nil {
+			return err
+		}
+		fmt.Printf("Reloaded %d links.\n", result.Links)
+		return nil
+	},
+}
+
+func init() {
+	reloadCmd.Flags().String("server", defaultServerURL, "URL of the running golink server")
+	reloadCmd.Flags().String("token", "", "Admin token for the server (default from admin_token config)")
+
+	rootCmd.AddCommand(reloadCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// Resolve command
+var resolveCmd = &cobra.Command{
+	Use:   "resolve [alias]",
+	Short: "Show where a go link ends up",
+	Long: `Follow a link's alias: references and print each step and the final
+URL, without opening anything. The link is resolved the way golink serve
+would redirect to it, with pattern links and the redirect_schemes and
+allowed_redirect_hosts settings, except that links with several targets
+use the first. Add #anchor to the alias, or pass --fragment, to put it on
+the final URL.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias, fragment, err := aliasFragment(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		params, _ := cmd.Flags().GetStringToString("param")
+		strictParams, _ := cmd.Flags().GetBool("strict-params")
+
+		loc, err := scheduleLocation()
+		if err != nil {
+			return err
+		}
+		ctx, err := resolveContext(params, strictParams, link.Now().In(loc))
+		if err != nil {
+			return err
+		}
+		res, err := server.Resolve(alias, ctx)
+
+		// Print each hop followed, so a broken chain shows how far it got
+		for i, hop := range res.Hops {
+			if i == 0 && hop != alias {
+				if p, ok := store.Lookup(hop); ok {
+					fmt.Printf("%s -> pattern %s (%s)\n", alias, p.Alias, p.Pattern)
+				}
+			}
+			if i+1 < len(res.Hops) {
+				fmt.Printf("%s -> alias:%s\n", hop, res.Hops[i+1])
+			}
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s\n", res.Hops[len(res.Hops)-1], link.WithFragment(res.URL, fragment))
+		return nil
+	},
+}
+
+// matchPattern matches an alias that has no link of its own against the
+// pattern links, like the server does
+func matchPattern(alias string) (*link.Link, string, bool) {
+	if _, ok := store.LookupRedirect(alias); ok {
+		return nil, "", false
+	}
+	return store.MatchPattern(alias)
+}
+
+// getLink is store.Get for commands that follow a link to its URL, which a
+// pattern link only has once it matches an alias
+func getLink(alias string) (*link.Link, error) {
+	l, err := store.Get(alias)
+	if err != nil {
+		return nil, err
+	}
+	if l.Pattern != "" {
+		return nil, fmt.Errorf("%s is a pattern link; give an alias it matches (%s)", alias, l.Pattern)
+	}
+	return l, nil
+}
+
+// firstTarget picks the link's scheduled URL if a rule matches now, or else
+// its first URL, for commands that resolve a link without a server's
+// round-robin state
+func firstTarget(l *link.Link) string {
+	// An invalid timezone is reported by config validation; use local time
+	loc, _ := scheduleLocation()
+	if target, ok := l.ScheduledURL(loc); ok {
+		return target
+	}
+	return l.URLs()[0]
+}
+
+// aliasFragment splits an alias argument like "docs#install" into the
+// normalized alias and the fragment, which may instead come from --fragment
+func aliasFragment(cmd *cobra.Command, arg string) (string, string, error) {
+	alias, fragment, _ := strings.Cut(arg, "#")
+	if flag, _ := cmd.Flags().GetString("fragment"); flag != "" {
+		if fragment != "" {
+			return "", "", usageError("give the fragment either after # or with --fragment, not both")
+		}
+		fragment = flag
+	}
+	return normalizeAlias(alias), fragment, nil
+}
+
+// followAliases follows a link's alias: references in the store
+func followAliases(l *link.Link) (*link.Link, string, error) {
+	return link.FollowAliases(l, firstTarget, store.LookupRedirect)
+}
+
+func init() {
+	resolveCmd.Flags().StringToString("param", nil, "Value for a {name} placeholder in the final URL (key=value, repeatable)")
+	resolveCmd.Flags().Bool("strict-params", false, "Fail if the final URL has placeholders without a --param value")
+	resolveCmd.Flags().String("fragment", "", "Anchor to add to the final URL, replacing its own (same as alias#fragment)")
+
+	rootCmd.AddCommand(resolveCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/server"
+	"github.com/spf13/viper"
+)
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	err = fn()
+	w.Close()
+	return <-out, err
+}
+
+// TestResolveCommandsAgree checks that resolve and test-resolve report the
+// URL server.Resolve gives, including for pattern links, placeholders and
+// targets the server refuses
+func TestResolveCommandsAgree(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("allowed_redirect_hosts", []string{"*.example.com"})
+
+	bug := link.NewLink("bug", "https://tracker.example.com/issue/$1", "", "")
+	bug.Pattern = `^bug/(\d+)$`
+	useTestStore(t,
+		bug,
+		link.NewLink("search", "https://search.example.com/?q={q}", "", ""),
+		link.NewLink("find", "alias:search", "", ""),
+		link.NewLink("notes", "file:///home/me/notes.txt", "", ""),
+		link.NewLink("elsewhere", "https://example.org", "", ""),
+	)
+
+	tests := []struct {
+		alias  string
+		params map[string]string // --param values for resolve
+		query  string            // The same values for test-resolve
+		want   string            // Empty when the target is refused
+	}{
+		{"bug/123", nil, "", "https://tracker.example.com/issue/123"},
+		{"notes", nil, "", ""},
+		{"elsewhere", nil, "", ""},
+		{"find", map[string]string{"q": "go & rust"}, "?q=go+%26+rust", "https://search.example.com/?q=go+%26+rust"},
+	}
+	for _, tt := range tests {
+		ctx, err := resolveContext(tt.params, false, link.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range tt.params {
+			if err := resolveCmd.Flags().Set("param", name+"="+value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		res, err := server.Resolve(tt.alias, ctx)
+
+		resolved, resolveErr := captureStdout(t, func() error { return resolveCmd.RunE(resolveCmd, []string{tt.alias}) })
+		tested, testErr := captureStdout(t, func() error { return testResolveCmd.RunE(testResolveCmd, []string{tt.alias + tt.query}) })
+
+		if tt.want == "" {
+			if !errors.Is(err, server.ErrTargetNotAllowed) || !errors.Is(resolveErr, server.ErrTargetNotAllowed) || !errors.Is(testErr, server.ErrTargetNotAllowed) {
+				t.Errorf("%s: Resolve, resolve and test-resolve returned %v, %v, %v; want all refused", tt.alias, err, resolveErr, testErr)
+			}
+			continue
+		}
+		if err != nil || res.URL != tt.want {
+			t.Errorf("%s: Resolve = %q, %v; want %q", tt.alias, res.URL, err, tt.want)
+		}
+		if resolveErr != nil || !strings.HasSuffix(resolved, " -> "+tt.want+"\n") {
+			t.Errorf("%s: resolve printed %q, %v; want it to end at %s", tt.alias, resolved, resolveErr, tt.want)
+		}
+		if testErr != nil || !strings.Contains(tested, " -> "+tt.want+"\n") {
+			t.Errorf("%s: test-resolve printed %q, %v; want %s", tt.alias, tested, testErr, tt.want)
+		}
+	}
+}
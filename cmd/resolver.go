@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hostsMarker tags the hosts file lines written by install-resolver so they can be removed
+const hostsMarker = "# added by golink"
+
+// Install resolver command
+var installResolverCmd = &cobra.Command{
+	Use:   "install-resolver",
+	Short: "Set up local resolution of go/ links",
+	Long: `Add a hosts file entry mapping the go hostname to 127.0.0.1, so
+http://go/alias reaches a golink server on this machine.
+
+Editing the hosts file usually requires root (or Administrator on
+Windows). If the server can't listen on port 80, start it with
+--proxy-autoconfig and point your browser or system proxy settings at
+its /proxy.pac URL instead. Run uninstall-resolver to undo.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hostsFile, _ := cmd.Flags().GetString("hosts-file")
+		port, _ := cmd.Flags().GetInt("port")
+
+		lines, err := readHostsFile(hostsFile)
+		if err != nil {
+			return err
+		}
+
+		installed := false
+		for _, line := range lines {
+			if strings.HasSuffix(line, hostsMarker) {
+				installed = true
+				break
+			}
+		}
+
+		if installed {
+			fmt.Printf("Resolver already installed in %s\n", hostsFile)
+		} else {
+			lines = append(lines, "127.0.0.1 go "+hostsMarker)
+			if err := writeHostsFile(hostsFile, lines); err != nil {
+				return err
+			}
+			fmt.Printf("Added go -> 127.0.0.1 to %s\n", hostsFile)
+		}
+
+		if port == 80 {
+			fmt.Println("Start the server with 'golink serve' and visit http://go/<alias>.")
+		} else {
+			fmt.Printf("The server isn't on port 80, so also start it with --proxy-autoconfig:\n\n")
+			fmt.Printf("  golink serve --port %d --proxy-autoconfig\n\n", port)
+			fmt.Printf("and set your proxy auto-config URL to http://127.0.0.1:%d/proxy.pac\n", port)
+		}
+		return nil
+	},
+}
+
+// Uninstall resolver command
+var uninstallResolverCmd = &cobra.Command{
+	Use:   "uninstall-resolver",
+	Short: "Remove the local go/ resolution set up by install-resolver",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hostsFile, _ := cmd.Flags().GetString("hosts-file")
+
+		lines, err := readHostsFile(hostsFile)
+		if err != nil {
+			return err
+		}
+
+		kept := lines[:0]
+		for _, line := range lines {
+			if !strings.HasSuffix(line, hostsMarker) {
+				kept = append(kept, line)
+			}
+		}
+
+		if len(kept) == len(lines) {
+			fmt.Printf("No golink entries found in %s\n", hostsFile)
+			return nil
+		}
+
+		if err := writeHostsFile(hostsFile, kept); err != nil {
+			return err
+		}
+		fmt.Printf("Removed golink entries from %s\n", hostsFile)
+		fmt.Println("Remember to remove any proxy auto-config URL you set up.")
+		return nil
+	},
+}
+
+// defaultHostsFile returns the hosts file location for the current OS
+func defaultHostsFile() string {
+	if runtime.GOOS == "windows" {
+		return `C:\Windows\System32\drivers\etc\hosts`
+	}
+	return "/etc/hosts"
+}
+
+// readHostsFile returns the lines of a hosts file
+func readHostsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// writeHostsFile replaces the contents of a hosts file, keeping its permissions
+func writeHostsFile(path string, lines []string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), info.Mode().Perm())
+}
+
+func init() {
+	installResolverCmd.Flags().String("hosts-file", defaultHostsFile(), "Hosts file to edit")
+	installResolverCmd.Flags().IntP("port", "p", 80, "Port the golink server listens on")
+	uninstallResolverCmd.Flags().String("hosts-file", defaultHostsFile(), "Hosts file to edit")
+
+	rootCmd.AddCommand(installResolverCmd, uninstallResolverCmd)
+}
@@ -2,22 +2,31 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/logfile"
+	"github.com/bkarpinos/golink/internal/platform"
 	"github.com/bkarpinos/golink/internal/server"
 	"github.com/bkarpinos/golink/internal/storage"
+	"github.com/bkarpinos/golink/internal/tree"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -25,6 +34,10 @@ var (
 	configDir  string // Directory containing config files
 	storageDir string // Directory to store links (configurable)
 	store      *storage.JSONStorage
+
+	// detectPlatform returns the platform to open URLs and use the
+	// clipboard with. Tests replace it with a platform.Mock.
+	detectPlatform = platform.Detect
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -37,43 +50,193 @@ go/links for quick navigation to frequently used URLs.. For example:
 go/meeting -> http://zoom.us/...
 go/drive -> https://docs.google.com/...
 go/gh -> https://github.com/...`,
+
+	// Execute prints errors and sets the exit code; usage is only shown for
+	// usage errors, as a hint
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Add command
 var addCmd = &cobra.Command{
-	Use:   "add [alias] [url]",
+	Use:   "add [alias] [url...]",
 	Short: "Add a new go link",
-	Args:  cobra.ExactArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
-		alias := args[0]
-		url := args[1]
+	Long: `Add a new go link. With --from-clipboard, the URL is read from the
+system clipboard instead of the command line.
+
+Links added without --category get the default_category from the config,
+if one is set. Pass --category "" to leave a link uncategorized.
+
+Several URLs may be given to spread redirects across mirrors or variants;
+the server picks one per request according to --target-policy.
+
+With --pattern, the link is a pattern link: requests for aliases without a
+link of their own that match the regular expression redirect to the URL,
+with $1 or ${name} replaced by the captured groups. The alias just names
+the pattern link. Pattern links are tried in the order they were added.
+
+  golink add bugs 'https://tracker.example.com/issue/$1' --pattern '^bug/(\d+)$'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if fromClipboard, _ := cmd.Flags().GetBool("from-clipboard"); fromClipboard {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.MinimumNArgs(2)(cmd, args)
+	},
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := normalizeAlias(args[0])
+		if alias != args[0] {
+			fmt.Fprintf(os.Stderr, "Note: alias %q will be stored as %q\n", args[0], alias)
+		}
 		description, _ := cmd.Flags().GetString("description")
 		category, _ := cmd.Flags().GetString("category")
+		redirect, _ := cmd.Flags().GetString("redirect-mode")
+		policy, _ := cmd.Flags().GetString("target-policy")
+
+		// An explicit --category, even an empty one, overrides the default
+		if !cmd.Flags().Changed("category") {
+			category = viper.GetString("default_category")
+		}
 
-		l := link.NewLink(alias, url, description, category)
+		if err := link.ValidateAlias(alias); err != nil {
+			return err
+		}
+		if err := link.ValidateRedirect(redirect); err != nil {
+			return err
+		}
+
+		var target string
+		if len(args) > 1 {
+			target = args[1]
+		} else {
+			var err error
+			if target, err = urlFromClipboard(); err != nil {
+				return err
+			}
+		}
+
+		l := link.NewLink(alias, target, description, category)
+		l.Redirect = redirect
+		l.Pattern, _ = cmd.Flags().GetString("pattern")
+		if len(args) > 2 {
+			l.Targets = args[1:]
+			l.TargetPolicy = policy
+		}
+		if err := l.ValidateURL(configList("link_schemes")); err != nil {
+			return err
+		}
+		if l.Pattern != "" {
+			if err := l.ValidatePattern(); err != nil {
+				return err
+			}
+		}
 		if err := store.Create(l); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return
+			return err
+		}
+		fmt.Printf("Created go link: %s -> %s\n", alias, strings.Join(l.URLs(), ", "))
+		warnRedirectHosts(alias, l.URLs()...)
+
+		// References may be added before the link they point at, so only warn
+		for _, target := range l.URLs() {
+			if ref, ok := link.AliasRef(target); ok {
+				if _, exists := store.LookupRedirect(ref); !exists {
+					fmt.Fprintf(os.Stderr, "Warning: %s refers to %s, which doesn't exist yet\n", alias, ref)
+				}
+			}
 		}
-		fmt.Printf("Created go link: %s -> %s\n", alias, url)
+		return nil
 	},
 }
 
+// warnRedirectHosts warns about a link's targets that the server would
+// refuse to redirect to under the allowed_redirect_hosts config. The link is
+// still saved, since the allowlist may be about to change.
+func warnRedirectHosts(alias string, targets ...string) {
+	// An invalid allowlist is reported by config validation
+	allowed, err := server.ParseHostAllowlist(configList("allowed_redirect_hosts"))
+	if err != nil {
+		return
+	}
+	for _, target := range targets {
+		if !allowed.Allows(target) {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not in allowed_redirect_hosts, so the server won't redirect %s there\n", target, alias)
+		}
+	}
+}
+
+// urlFromClipboard reads a URL from the system clipboard, checking that the
+// clipboard actually holds one
+func urlFromClipboard() (string, error) {
+	p, err := detectPlatform()
+	if err != nil {
+		return "", err
+	}
+
+	text, err := p.ReadClipboard()
+	if err != nil {
+		return "", fmt.Errorf("could not read clipboard: %v", err)
+	}
+
+	text = strings.TrimSpace(text)
+	u, err := url.Parse(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		if len(text) > 60 {
+			text = text[:60] + "..."
+		}
+		return "", fmt.Errorf("clipboard does not contain an http(s) URL: %q", text)
+	}
+	return text, nil
+}
+
 // List command
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all go links",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		columns, _ := cmd.Flags().GetStringSlice("columns")
+		for _, c := range columns {
+			if _, ok := listColumns[c]; !ok {
+				return usageError("unknown column %q (valid columns: %s)", c, strings.Join(listColumnNames(), ", "))
+			}
+		}
+
 		links := store.List()
 		if len(links) == 0 {
 			fmt.Println("No links found.")
-			return
+			return nil
+		}
+
+		if neverUpdated, _ := cmd.Flags().GetBool("never-updated"); neverUpdated {
+			links = slices.DeleteFunc(links, func(l *link.Link) bool { return !l.NeverUpdated() })
+			if len(links) == 0 {
+				fmt.Println("No links match.")
+				return nil
+			}
+		}
+
+		if len(columns) > 0 {
+			printLinkTable(os.Stdout, links, columns)
+			return nil
+		}
+
+		if showTree, _ := cmd.Flags().GetBool("tree"); showTree {
+			tree.Walk(tree.Build(links, viper.GetString("display_separator")), func(prefix string, n *tree.Node) {
+				if n.Link == nil {
+					fmt.Printf("%s%s\n", prefix, n.Name)
+					return
+				}
+				fmt.Printf("%s%s → %s\n", prefix, n.Name, n.Link.URL)
+			})
+			return nil
 		}
 
 		fmt.Println("Go Links:")
 		fmt.Println("=========")
 		for _, link := range links {
 			fmt.Printf("%-15s -> URL: %s\n", link.Alias, link.URL)
+			if link.Pattern != "" {
+				fmt.Printf("%18s Pattern: %s\n", "", link.Pattern)
+			}
 			if link.Description != "" {
 				fmt.Printf("%18s Description: %s\n", "", link.Description)
 			}
@@ -82,97 +245,369 @@ var listCmd = &cobra.Command{
 			}
 			fmt.Println()
 		}
+		return nil
 	},
 }
 
+// listColumns maps the column names accepted by list --columns to their values
+var listColumns = map[string]func(*link.Link) string{
+	"alias":       func(l *link.Link) string { return l.Alias },
+	"url":         func(l *link.Link) string { return l.URL },
+	"targets":     func(l *link.Link) string { return strings.Join(l.URLs(), ",") },
+	"description": func(l *link.Link) string { return l.Description },
+	"category":    func(l *link.Link) string { return l.Category },
+	"created":     func(l *link.Link) string { return l.CreatedAt.Format("2006-01-02 15:04") },
+	"updated":     func(l *link.Link) string { return l.UpdatedAt.Format("2006-01-02 15:04") },
+}
+
+// listColumnNames returns the valid column names in sorted order
+func listColumnNames() []string {
+	names := make([]string, 0, len(listColumns))
+	for name := range listColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printLinkTable writes links as an aligned table with the given columns, sorted by alias
+func printLinkTable(out io.Writer, links []*link.Link, columns []string) {
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Alias < links[j].Alias
+	})
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, l := range links {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			values[i] = listColumns[c](l)
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	tw.Flush()
+}
+
 // Open command
 var openCmd = &cobra.Command{
 	Use:   "open [alias]",
 	Short: "Open a go link in the default browser",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		alias := args[0]
-		link, err := store.Get(alias)
+	Long: `Open a go link in the default browser. Add #anchor to the alias, or pass
+--fragment, to jump to a section of the page; it replaces any fragment the
+link's URL already has.
+
+  golink open docs#installation`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias, fragment, err := aliasFragment(cmd, args[0])
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return
+			return err
 		}
-
 		useDirectURL, _ := cmd.Flags().GetBool("direct")
+		params, _ := cmd.Flags().GetStringToString("param")
+		strictParams, _ := cmd.Flags().GetBool("strict-params")
+		newWindow, _ := cmd.Flags().GetBool("new-window")
+
+		// The browser and profile default to the browser and browser_profile config
+		browser, _ := cmd.Flags().GetString("browser")
+		if !cmd.Flags().Changed("browser") {
+			browser = viper.GetString("browser")
+		}
+		profile, _ := cmd.Flags().GetString("profile")
+		if !cmd.Flags().Changed("profile") {
+			profile = viper.GetString("browser_profile")
+		}
+		if err := platform.ValidateBrowser(browser); err != nil {
+			return usageError("--browser: %v", err)
+		}
+		if browser == "" && (profile != "" || newWindow) {
+			return usageError("--profile and --new-window require --browser or the browser config")
+		}
+
+		// Follow alias: references (or match a pattern link) and fill the
+		// URL template locally, even when opening via go/, so missing links
+		// and parameters are reported before the browser is launched
+		final, raw, ok := matchPattern(alias)
+		if !ok {
+			l, err := getLink(alias)
+			if err != nil {
+				return err
+			}
+			if final, raw, err = followAliases(l); err != nil {
+				return err
+			}
+		}
+		expanded, err := final.ExpandURL(raw, params, strictParams)
+		if err != nil {
+			return err
+		}
+		expanded = link.WithFragment(expanded, fragment)
+
+		// Browsers keep the go/ URL's fragment across the redirect only when
+		// the target has none of its own, so open others directly
+		if fragment != "" && strings.Contains(raw, "#") {
+			useDirectURL = true
+		}
 
 		var urlToOpen string
-		if useDirectURL {
-			urlToOpen = link.URL
+		if !final.IsWeb() {
+			// Browsers won't follow a redirect to most other schemes, so hand
+			// deeplinks and file:// targets straight to the system opener
+			urlToOpen = expanded
+			fmt.Printf("Opening %s (%s)\n", alias, urlToOpen)
+		} else if useDirectURL {
+			urlToOpen = expanded
 			fmt.Printf("Opening %s (%s) in browser\n", alias, urlToOpen)
 		} else {
-			// Create golink URL format
-			urlToOpen = fmt.Sprintf("http://go/%s", link.Alias)
+			// Create golink URL format, passing parameters as the query string
+			urlToOpen = fmt.Sprintf("http://go/%s", alias)
+			if len(params) > 0 {
+				query := url.Values{}
+				for k, v := range params {
+					query.Set(k, v)
+				}
+				urlToOpen += "?" + query.Encode()
+			}
+			urlToOpen = link.WithFragment(urlToOpen, fragment)
 			fmt.Printf("Opening %s (%s) in browser\n", alias, urlToOpen)
 		}
 
-		// Open URL in the default browser
-		var openCmd *exec.Cmd
-		switch runtime.GOOS {
-		case "darwin":
-			openCmd = exec.Command("open", urlToOpen)
-		case "linux":
-			openCmd = exec.Command("xdg-open", urlToOpen)
-		case "windows":
-			openCmd = exec.Command("cmd", "/c", "start", urlToOpen)
-		default:
-			fmt.Fprintf(os.Stderr, "Unsupported operating system: %s\n", runtime.GOOS)
-			return
-		}
-
-		err = openCmd.Run()
+		p, err := detectPlatform()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error opening URL: %v\n", err)
+			return err
+		}
+
+		// Open web links in the chosen browser, if any. Deeplinks always go
+		// to the system opener, which knows which app handles them.
+		if browser != "" && final.IsWeb() {
+			err := p.OpenURLIn(browser, profile, newWindow, urlToOpen)
+			if !errors.Is(err, platform.ErrBrowserNotFound) {
+				if err != nil {
+					return fmt.Errorf("opening URL: %w", err)
+				}
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v, using the default browser\n", err)
 		}
+
+		// Open URL in the default browser
+		if err := p.OpenURL(urlToOpen); err != nil {
+			return fmt.Errorf("opening URL: %w", err)
+		}
+		return nil
 	},
 }
 
 // Delete command
 var deleteCmd = &cobra.Command{
-	Use:   "delete [alias]",
-	Short: "Delete a go link",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		alias := args[0]
+	Use:         "delete [alias]",
+	Short:       "Delete a go link",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := normalizeAlias(args[0])
 		if err := store.Delete(alias); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return
+			return err
 		}
 		fmt.Printf("Deleted go link: %s\n", alias)
+		return nil
 	},
 }
 
 // Serve command
 var serveCmd = &cobra.Command{
-	Use:   "serve",
-	Short: "Start the go links HTTP server",
-	Run: func(cmd *cobra.Command, args []string) {
-		port, _ := cmd.Flags().GetInt("port")
-		notFoundURL, _ := cmd.Flags().GetString("not-found")
+	Use:         "serve",
+	Short:       "Start the go links HTTP server",
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Bind flags to config keys, so each setting can also come from
+		// GOLINK_* environment variables or the config file. This is done here
+		// rather than in init so commands that write the config don't save
+		// every serve default along with it.
+		bindFlags(cmd)
+
+		port := viper.GetInt("port")
+		notFoundURL := viper.GetString("not_found")
+		strictParams := viper.GetBool("strict_params")
+		redirectSchemes := configList("redirect_schemes")
+		redirectMode := viper.GetString("redirect_mode")
+		beaconURL := viper.GetString("beacon_url")
+		proxyAutoConfig := viper.GetBool("proxy_autoconfig")
+		favicons := viper.GetBool("favicons")
+		eventsURL := viper.GetString("events_url")
+		eventsMetadata := viper.GetBool("events_metadata")
+		trustedProxyList := configList("trusted_proxies")
+		adminToken := viper.GetString("admin_token")
+		logSample := viper.GetFloat64("log_sample")
+		logSkip := configList("log_skip")
+		logLevel := viper.GetString("log_level")
+		logFile := viper.GetString("log_file")
+		logMaxSize := viper.GetInt64("log_max_size")
+		logMaxAge := viper.GetDuration("log_max_age")
+		logBackups := viper.GetInt("log_backups")
+		blockPrivate := viper.GetBool("block_private")
+		allowPrivateList := configList("allow_private")
+		idleShutdown := viper.GetDuration("idle_shutdown")
+		idleCountHealth := viper.GetBool("idle_count_health")
+		monitorInterval := viper.GetDuration("monitor_interval")
+		monitorTimeout := viper.GetDuration("monitor_timeout")
+		monitorConcurrency := viper.GetInt("monitor_concurrency")
+		rpcPort := viper.GetInt("rpc_port")
+		openPage := viper.GetString("open")
+
+		allowedHosts, err := server.ParseHostAllowlist(configList("allowed_redirect_hosts"))
+		if err != nil {
+			return usageError("invalid --allowed-redirect-hosts: %v", err)
+		}
+		if notFoundURL != "" && !allowedHosts.Allows(notFoundURL) {
+			return usageError("--not-found URL %s is not in --allowed-redirect-hosts", notFoundURL)
+		}
+
+		location, err := scheduleLocation()
+		if err != nil {
+			return usageError("--timezone: %v", err)
+		}
+
+		if err := link.ValidateRedirect(redirectMode); err != nil {
+			return usageError("--redirect-mode: %v", err)
+		}
+		if redirectMode == link.RedirectBeacon && beaconURL == "" {
+			return usageError("--redirect-mode beacon requires --beacon-url")
+		}
+
+		if logSample < 0 || logSample > 1 {
+			return usageError("--log-sample must be between 0 and 1")
+		}
+		if err := server.ValidateLogLevel(logLevel); err != nil {
+			return usageError("--log-level: %v", err)
+		}
+		if logMaxSize < 0 || logMaxAge < 0 || logBackups < 0 {
+			return usageError("--log-max-size, --log-max-age and --log-backups can't be negative")
+		}
+
+		// Send the log to a rotating file instead of stderr. SIGHUP reopens
+		// it, for when an external tool like logrotate moves it away.
+		if logFile != "" {
+			w, err := logfile.Open(logFile, logMaxSize<<20, logMaxAge, logBackups)
+			if err != nil {
+				return fmt.Errorf("opening log file: %w", err)
+			}
+			defer w.Close()
+			log.SetOutput(w)
+			defer log.SetOutput(os.Stderr)
+
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+			go func() {
+				for range hup {
+					if err := w.Reopen(); err != nil {
+						log.Printf("Error reopening log file: %v", err)
+					}
+				}
+			}()
+		}
+
+		trustedProxies, err := server.ParseNetworks(trustedProxyList)
+		if err != nil {
+			return usageError("invalid --trusted-proxies: %v", err)
+		}
+		if len(trustedProxies) > 0 && adminToken == "" {
+			fmt.Fprintln(os.Stderr, "Warning: admin endpoints like /api/reload are disabled behind --trusted-proxies without --admin-token")
+		}
+
+		allowPrivate, err := server.ParseNetworks(allowPrivateList)
+		if err != nil {
+			return usageError("invalid --allow-private: %v", err)
+		}
 
 		// Create the server
-		srv := server.NewServer(store, port, notFoundURL)
+		srv := server.NewServer(store, server.Options{
+			Port:           port,
+			NotFoundURL:    notFoundURL,
+			EventsURL:      eventsURL,
+			EventsMetadata: eventsMetadata,
+
+			DisplaySeparator: viper.GetString("display_separator"),
+			StrictParams:     strictParams,
+			AliasSpace:       aliasSpace(),
+			RedirectSchemes:  redirectSchemes,
+
+			AllowedRedirectHosts: allowedHosts,
+			ProxyAutoConfig:      proxyAutoConfig,
+			Favicons:             favicons,
+
+			Categories: categoryStyles(),
+
+			RedirectMode: redirectMode,
+			BeaconURL:    beaconURL,
+			Location:     location,
+
+			TrustedProxies: trustedProxies,
+			AdminToken:     adminToken,
+
+			LogSample:    logSample,
+			LogSkipPaths: logSkip,
+			LogLevel:     logLevel,
+
+			BlockPrivate: blockPrivate,
+			AllowPrivate: allowPrivate,
+
+			IdleShutdown:    idleShutdown,
+			IdleCountHealth: idleCountHealth,
+
+			MonitorInterval:    monitorInterval,
+			MonitorTimeout:     monitorTimeout,
+			MonitorConcurrency: monitorConcurrency,
+
+			RPCPort:     rpcPort,
+			LinkSchemes: configList("link_schemes"),
+		})
 
 		// Handle graceful shutdown
 		stop := make(chan os.Signal, 1)
 		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 		// Start the server in a goroutine
+		serveErr := make(chan error, 1)
 		go func() {
 			if err := srv.Start(); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("Server error: %v", err)
+				serveErr <- err
 			}
 		}()
 
-		// Wait for interrupt signal
-		<-stop
+		// Open the page once the server is listening, so the browser doesn't
+		// hit a refused connection
+		if openPage != "" {
+			go func() {
+				<-srv.Ready()
+				pageURL := srv.URL() + "/" + strings.TrimPrefix(openPage, "/")
+				p, err := detectPlatform()
+				if err == nil {
+					err = p.OpenURL(pageURL)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: couldn't open %s: %v\n", pageURL, err)
+				}
+			}()
+		}
+
+		// Wait for interrupt signal, or for the server to go idle
+		select {
+		case err := <-serveErr:
+			return fmt.Errorf("server: %w", err)
+		case <-stop:
+			fmt.Println("\nShutting down server...")
+		case <-srv.Idle():
+			fmt.Printf("No requests for %s, shutting down server...\n", idleShutdown)
+		}
 
 		// Shutdown gracefully with timeout
-		fmt.Println("\nShutting down server...")
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -181,9 +616,64 @@ var serveCmd = &cobra.Command{
 		}
 
 		fmt.Println("Server stopped")
+		return nil
 	},
 }
 
+// renamedKeys maps config keys to the names they replaced. The old names
+// are still read, with a warning, when the new key isn't set.
+var renamedKeys = map[string]string{
+	"link_schemes":     "allowed_schemes",
+	"redirect_schemes": "allow_scheme",
+}
+
+// configList returns a list setting, splitting comma-separated values as
+// they arrive from environment variables
+func configList(key string) []string {
+	if old, ok := renamedKeys[key]; ok && !viper.IsSet(key) {
+		key = old
+	}
+	var result []string
+	for _, v := range viper.GetStringSlice(key) {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// aliasSpace returns the character spaces in aliases are replaced with,
+// from the alias_space config. It defaults to "-"; setting it to "" turns
+// normalization off.
+func aliasSpace() string {
+	if !viper.IsSet("alias_space") {
+		return "-"
+	}
+	return viper.GetString("alias_space")
+}
+
+// storagePretty reports whether the links file is saved indented, from the
+// storage_pretty config. It defaults to true.
+func storagePretty() bool {
+	return !viper.IsSet("storage_pretty") || viper.GetBool("storage_pretty")
+}
+
+// normalizeAlias returns the canonical form of an alias typed on the command
+// line, e.g. "team meeting" -> "team-meeting"
+func normalizeAlias(alias string) string {
+	return link.NormalizeAlias(alias, aliasSpace())
+}
+
+// bindFlags binds each of a command's flags to the config key of the same
+// name with dashes replaced by underscores (e.g. --not-found -> not_found)
+func bindFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		viper.BindPFlag(strings.ReplaceAll(f.Name, "-", "_"), f)
+	})
+}
+
 // Config command
 var configCmd = &cobra.Command{
 	Use:   "config",
@@ -195,14 +685,13 @@ var setStorageDirCmd = &cobra.Command{
 	Use:   "storage-dir [path]",
 	Short: "Set the directory to store links",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		path := args[0]
 		// Convert to absolute path if needed
 		if !filepath.IsAbs(path) {
 			absPath, err := filepath.Abs(path)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error converting to absolute path: %v\n", err)
-				return
+				return fmt.Errorf("converting to absolute path: %w", err)
 			}
 			path = absPath
 		}
@@ -211,29 +700,31 @@ var setStorageDirCmd = &cobra.Command{
 		viper.Set("storage_dir", path)
 
 		// Write config
-		if err := viper.WriteConfig(); err != nil {
-			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-				// Config file doesn't exist yet
-				if err := viper.SafeWriteConfigAs(filepath.Join(configDir, "config.yaml")); err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
-					return
-				}
-			} else {
-				fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
-				return
-			}
+		if err := writeConfig(); err != nil {
+			return fmt.Errorf("writing config: %w", err)
 		}
 
 		fmt.Printf("Storage directory set to: %s\n", path)
 		fmt.Println("Restart the application for changes to take effect.")
+		return nil
 	},
 }
 
+// writeConfig saves the current settings to the config file, creating it
+// if it doesn't exist yet
+func writeConfig() error {
+	err := viper.WriteConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		return viper.SafeWriteConfigAs(filepath.Join(configDir, "config.yaml"))
+	}
+	return err
+}
+
 // View config command
 var viewConfigCmd = &cobra.Command{
 	Use:   "view",
 	Short: "View current configuration",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Config directory: %s\n", configDir)
 		fmt.Printf("Storage directory: %s\n", storageDir)
 		if viper.ConfigFileUsed() != "" {
@@ -247,14 +738,22 @@ var viewConfigCmd = &cobra.Command{
 		for k, v := range settings {
 			fmt.Printf("  %s: %v\n", k, v)
 		}
+		return nil
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// Commands report failures by returning an error, which is printed here and
+// turned into the exit code.
 func Execute() {
-	err := rootCmd.Execute()
+	cmd, err := rootCmd.ExecuteC()
 	if err != nil {
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		code := exitCode(err)
+		if code == exitUsage {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
+		}
+		os.Exit(code)
 	}
 }
 
@@ -269,7 +768,9 @@ func initConfig() {
 	viper.SetConfigName("config")
 	viper.AddConfigPath(configDir)
 
-	// Read in environment variables that match
+	// Read in environment variables that match, e.g. GOLINK_STORAGE_DIR
+	viper.SetEnvPrefix("golink")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 
 	// If config file exists, read it in
@@ -294,13 +795,35 @@ func initConfig() {
 	if err := os.MkdirAll(storageDir, 0755); err != nil {
 		log.Fatalf("Failed to create storage directory: %v", err)
 	}
+}
+
+// skipStoreAnnotation marks commands that must not open (and so create or
+// watch) the links store
+const skipStoreAnnotation = "golink_skip_store"
+
+// writesStoreAnnotation marks commands that write to the storage directory,
+// which config validation checks is writable before they run
+const writesStoreAnnotation = "golink_writes_store"
+
+// initStore opens the link storage before a command runs. Shell completion
+// requests skip it and read the much smaller completion index instead.
+func initStore(cmd *cobra.Command, args []string) error {
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return nil
+	}
+	if cmd.Annotations[skipStoreAnnotation] != "" {
+		return nil
+	}
 
 	// Initialize storage with the correct directory
 	var err error
-	store, err = storage.NewJSONStorage(filepath.Join(storageDir, "links.json"))
+	store, err = storage.NewJSONStorage(filepath.Join(storageDir, "links.json"),
+		storage.WithPassphrase(os.Getenv(passphraseEnv)),
+		storage.WithPretty(storagePretty()))
 	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
+	return nil
 }
 
 func init() {
@@ -315,8 +838,16 @@ func init() {
 	}
 	configDir = filepath.Join(homeDir, ".config", "golink")
 
-	// Initialize config before executing commands
+	// Initialize config before executing commands, then open storage
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		commandStarted = true
+		if err := checkConfig(cmd); err != nil {
+			return err
+		}
+		return initStore(cmd, args)
+	}
+	rootCmd.PersistentFlags().Bool("strict-config", false, "Refuse to run when the configuration has problems, instead of warning")
 
 	// // Create storage
 	// store, err = storage.NewJSONStorage(filepath.Join(configDir, "links.json"))
@@ -327,14 +858,65 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	addCmd.Flags().StringP("description", "d", "", "Description of the link")
-	addCmd.Flags().StringP("category", "c", "", "Category for the link")
+	addCmd.Flags().StringP("category", "c", "", "Category for the link (default from default_category config)")
+	addCmd.Flags().Bool("from-clipboard", false, "Read the URL from the system clipboard")
+	addCmd.Flags().String("target-policy", link.PolicyRoundRobin, "How the server picks between several URLs: round-robin or random")
+	addCmd.Flags().String("pattern", "", "Make this a pattern link matching requested aliases against a regular expression, e.g. '^bug/(\\d+)$'")
+	addCmd.Flags().String("redirect-mode", "", "How the server redirects this link: 302, or beacon (default: the server's --redirect-mode)")
+
+	listCmd.Flags().Bool("tree", false, "Show links as a tree grouped by category and display_separator")
+	listCmd.Flags().Bool("never-updated", false, "Only show links that haven't been changed since they were created")
+	listCmd.Flags().StringSlice("columns", nil, "Comma-separated columns to show as a table (alias, url, targets, description, category, created, updated)")
 
 	// Add flags for the serve command
 	serveCmd.Flags().IntP("port", "p", 80, "Port to serve on")
 	serveCmd.Flags().String("not-found", "", "URL to redirect to when a go link is not found (optional)")
+	serveCmd.Flags().StringSlice("redirect-schemes", nil, "Non-HTTP URL schemes to redirect to, e.g. slack,vscode (others get 403)")
+	serveCmd.Flags().StringSlice("allow-scheme", nil, "Deprecated name for --redirect-schemes")
+	serveCmd.Flags().MarkDeprecated("allow-scheme", "use --redirect-schemes instead")
+	serveCmd.Flags().StringSlice("allowed-redirect-hosts", nil, "Only redirect to these hosts, e.g. example.com,*.example.com (others get 403; default: any host)")
+	serveCmd.Flags().String("redirect-mode", link.RedirectFound, "Default redirect for links without their own: 302, or beacon for a page that fires --beacon-url first")
+	serveCmd.Flags().String("beacon-url", "", "Analytics URL the beacon page requests before redirecting; {alias} is replaced with the alias")
+	serveCmd.Flags().Bool("strict-params", false, "Return 400 when a templated link is missing query parameters")
+	serveCmd.Flags().String("timezone", "", "Timezone link schedules are evaluated in, e.g. Europe/Berlin (default: local time)")
+	serveCmd.Flags().Bool("proxy-autoconfig", false, "Serve a PAC file at /proxy.pac that routes go/* to this server")
+	serveCmd.Flags().Bool("favicons", false, "Show site favicons on the homepage, fetched and cached by the server")
+	serveCmd.Flags().StringSlice("trusted-proxies", nil, "IPs or CIDR ranges of reverse proxies whose X-Forwarded-For/X-Real-IP headers are trusted")
+	serveCmd.Flags().Float64("log-sample", 1, "Fraction of successful requests to log, e.g. 0.1 (errors and not-founds are always logged)")
+	serveCmd.Flags().StringSlice("log-skip", []string{"/healthz", "/favicon.ico", "/favicon-proxy"}, "Paths whose successful requests are not logged")
+	serveCmd.Flags().String("log-level", server.LogInfo, "Which requests to log: debug (all), info, warn (errors and not-founds) or error (server errors)")
+	serveCmd.Flags().String("log-file", "", "Write the log to this file instead of stderr, rotating it by size and age")
+	serveCmd.Flags().Int64("log-max-size", 100, "Rotate the log file once it reaches this many megabytes (0 disables)")
+	serveCmd.Flags().Duration("log-max-age", 0, "Rotate the log file after this long, e.g. 24h (disabled by default)")
+	serveCmd.Flags().Int("log-backups", 5, "Number of rotated log files to keep (0 keeps all)")
+	serveCmd.Flags().String("admin-token", "", "Bearer token required for admin endpoints like /api/reload (default: allow only local requests)")
+	serveCmd.Flags().Bool("block-private", false, "Refuse to fetch link targets on private, loopback or link-local addresses (monitor, favicons)")
+	serveCmd.Flags().StringSlice("allow-private", nil, "IPs or CIDR ranges exempt from --block-private")
+	serveCmd.Flags().String("events-url", "", "Webhook URL to POST redirect events to as JSON (optional)")
+	serveCmd.Flags().Bool("events-metadata", false, "Include client IP, user agent and referer in redirect events")
+	serveCmd.Flags().Duration("idle-shutdown", 0, "Stop the server after this long without requests, e.g. 30m (disabled by default)")
+	serveCmd.Flags().Bool("idle-count-health", false, "Count /healthz requests as activity for --idle-shutdown")
+	serveCmd.Flags().Duration("monitor-interval", 0, "Check link targets are reachable this often, e.g. 1h (disabled by default)")
+	serveCmd.Flags().Duration("monitor-timeout", 10*time.Second, "Timeout for each reachability check")
+	serveCmd.Flags().Int("monitor-concurrency", 4, "Maximum number of reachability checks to run at once")
+	serveCmd.Flags().String("open", "", "Open the homepage in the browser once the server is up; --open=info opens another page")
+	serveCmd.Flags().Lookup("open").NoOptDefVal = "/"
+	serveCmd.Flags().Int("rpc-port", 0, "Port to serve the JSON-RPC API on, for managing links from other programs (disabled by default)")
 
 	// Add direct flag to open command
 	openCmd.Flags().BoolP("direct", "d", false, "Open the direct URL instead of the go/link format")
+	openCmd.Flags().StringToString("param", nil, "Value for a {name} placeholder in the link's URL (key=value, repeatable)")
+	openCmd.Flags().Bool("strict-params", false, "Fail if the link's URL has placeholders without a --param value")
+	openCmd.Flags().String("fragment", "", "Anchor to open the page at, replacing the URL's own (same as alias#fragment)")
+	openCmd.Flags().String("browser", "", "Browser to open the link in: "+strings.Join(platform.BrowserNames(), ", ")+" (default from browser config, else the system default)")
+	openCmd.Flags().String("profile", "", "Browser profile to use, e.g. \"Profile 1\" for Chromium browsers (default from browser_profile config)")
+	openCmd.Flags().Bool("new-window", false, "Open the link in a new browser window")
+
+	// Complete aliases and categories from the completion index
+	openCmd.ValidArgsFunction = completeAliases
+	deleteCmd.ValidArgsFunction = completeAliases
+	addCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	openCmd.RegisterFlagCompletionFunc("browser", cobra.FixedCompletions(platform.BrowserNames(), cobra.ShellCompDirectiveNoFileComp))
 
 	// Add commands to root
 	rootCmd.AddCommand(addCmd, listCmd, openCmd, deleteCmd, serveCmd)
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestBindFlagsPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		env      map[string]string
+		args     []string
+		port     int
+		notFound string
+	}{
+		{"default", "", nil, nil, 8080, ""},
+		{"config over default", "port: 7000\nnot_found: https://config.example.com\n", nil, nil, 7000, "https://config.example.com"},
+		{
+			"env over config",
+			"port: 7000\nnot_found: https://config.example.com\n",
+			map[string]string{"GOLINK_PORT": "7100", "GOLINK_NOT_FOUND": "https://env.example.com"},
+			nil, 7100, "https://env.example.com",
+		},
+		{
+			"flag over env",
+			"port: 7000\nnot_found: https://config.example.com\n",
+			map[string]string{"GOLINK_PORT": "7100", "GOLINK_NOT_FOUND": "https://env.example.com"},
+			[]string{"--port", "7200", "--not-found", "https://flag.example.com"},
+			7200, "https://flag.example.com",
+		},
+		{"flag over config", "port: 7000\n", nil, []string{"--port=7200"}, 7200, ""},
+		{"env over default", "", map[string]string{"GOLINK_PORT": "7100"}, nil, 7100, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+
+			oldConfigDir, oldStorageDir := configDir, storageDir
+			configDir = t.TempDir()
+			t.Cleanup(func() { configDir, storageDir = oldConfigDir, oldStorageDir })
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			initConfig()
+
+			// The same kinds of flags as serve, with dashes in their names
+			cmd := &cobra.Command{Use: "serve"}
+			cmd.Flags().Int("port", 8080, "")
+			cmd.Flags().String("not-found", "", "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			bindFlags(cmd)
+
+			if got := viper.GetInt("port"); got != tt.port {
+				t.Errorf("port = %d, want %d", got, tt.port)
+			}
+			if got := viper.GetString("not_found"); got != tt.notFound {
+				t.Errorf("not_found = %q, want %q", got, tt.notFound)
+			}
+		})
+	}
+}
+
+func TestRenamedSchemeKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		link     []string
+		redirect []string
+	}{
+		{"new names", "link_schemes: [slack, file]\nredirect_schemes: [slack]\n", []string{"slack", "file"}, []string{"slack"}},
+		{"old names", "allowed_schemes: [vscode]\nallow_scheme: [vscode]\n", []string{"vscode"}, []string{"vscode"}},
+		{"new names win", "allowed_schemes: [vscode]\nlink_schemes: [file]\n", []string{"file"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			viper.SetConfigType("yaml")
+			if err := viper.ReadConfig(strings.NewReader(tt.config)); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := configList("link_schemes"); !slices.Equal(got, tt.link) {
+				t.Errorf("link_schemes = %q, want %q", got, tt.link)
+			}
+			if got := configList("redirect_schemes"); !slices.Equal(got, tt.redirect) {
+				t.Errorf("redirect_schemes = %q, want %q", got, tt.redirect)
+			}
+		})
+	}
+}
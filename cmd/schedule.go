@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Send a link somewhere else at certain times",
+	Long: `Manage a link's schedule: rules that send it to another URL during a
+time window, e.g. a different meeting room each weekday. Rules are checked
+in order and the first match wins; outside every window the link's own URL
+is used. Times are evaluated in the timezone config (default: local time).
+
+  golink schedule add standup https://meet.example.com/room-a --days mon,wed
+  golink schedule add standup https://meet.example.com/room-b --days tue,thu --from 09:00 --until 10:00`,
+}
+
+// Schedule add command
+var scheduleAddCmd = &cobra.Command{
+	Use:               "add [alias] [url]",
+	Short:             "Add a schedule rule to the end of a link's schedule",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliases,
+	Annotations:       map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := store.Get(normalizeAlias(args[0]))
+		if err != nil {
+			return err
+		}
+
+		rule := link.ScheduleRule{URL: args[1]}
+		rule.Days, _ = cmd.Flags().GetStringSlice("days")
+		rule.From, _ = cmd.Flags().GetString("from")
+		rule.Until, _ = cmd.Flags().GetString("until")
+		rule.Start, _ = cmd.Flags().GetString("start")
+		rule.End, _ = cmd.Flags().GetString("end")
+
+		l.Schedule = append(l.Schedule, rule)
+		if err := l.ValidateURL(configList("link_schemes")); err != nil {
+			return err
+		}
+		if err := store.Update(l); err != nil {
+			return err
+		}
+		fmt.Printf("Added rule %d to %s: %s -> %s\n", len(l.Schedule), l.Alias, rule, rule.URL)
+		warnRedirectHosts(l.Alias, rule.URL)
+		return nil
+	},
+}
+
+// Schedule list command
+var scheduleListCmd = &cobra.Command{
+	Use:               "list [alias]",
+	Short:             "Show a link's schedule and which rule applies now",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := store.Get(normalizeAlias(args[0]))
+		if err != nil {
+			return err
+		}
+
+		loc, err := scheduleLocation()
+		if err != nil {
+			return err
+		}
+
+		// Only the first matching rule applies, so mark just that one
+		active := false
+		t := time.Now().In(loc)
+		for i, rule := range l.Schedule {
+			marker := " "
+			if !active && rule.Matches(t) {
+				marker, active = "*", true
+			}
+			fmt.Printf("%s %d. %-30s -> %s\n", marker, i+1, rule, rule.URL)
+		}
+
+		marker := " "
+		if !active {
+			marker = "*"
+		}
+		fmt.Printf("%s    %-30s -> %s\n", marker, "otherwise", strings.Join(l.URLs(), ", "))
+		return nil
+	},
+}
+
+// Schedule remove command
+var scheduleRemoveCmd = &cobra.Command{
+	Use:               "remove [alias] [rule]",
+	Short:             "Remove a rule, by its number in schedule list, from a link's schedule",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAliases,
+	Annotations:       map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := store.Get(normalizeAlias(args[0]))
+		if err != nil {
+			return err
+		}
+
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(l.Schedule) {
+			return usageError("%s has no schedule rule %q", l.Alias, args[1])
+		}
+
+		l.Schedule = append(l.Schedule[:n-1], l.Schedule[n:]...)
+		if err := store.Update(l); err != nil {
+			return err
+		}
+		fmt.Printf("Removed rule %d from %s\n", n, l.Alias)
+		return nil
+	},
+}
+
+// scheduleLocation returns the timezone link schedules are evaluated in,
+// from the timezone config. It defaults to local time.
+func scheduleLocation() (*time.Location, error) {
+	name := viper.GetString("timezone")
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", name, err)
+	}
+	return loc, nil
+}
+
+func init() {
+	scheduleAddCmd.Flags().StringSlice("days", nil, "Weekdays the rule applies on, e.g. mon,wed,fri (default: every day)")
+	scheduleAddCmd.Flags().String("from", "", "Time of day the rule starts, as HH:MM")
+	scheduleAddCmd.Flags().String("until", "", "Time of day the rule ends, as HH:MM (before --from wraps past midnight)")
+	scheduleAddCmd.Flags().String("start", "", "First date the rule applies, as YYYY-MM-DD")
+	scheduleAddCmd.Flags().String("end", "", "Last date the rule applies, as YYYY-MM-DD")
+
+	scheduleCmd.AddCommand(scheduleAddCmd, scheduleListCmd, scheduleRemoveCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
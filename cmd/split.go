@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// Split command
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Write links to separate files by category",
+	Long: `Write one links file per category, so ownership of each category
+can be delegated. Each file uses the same format as links.json. Links
+without a category are written to uncategorized.json.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, _ := cmd.Flags().GetString("by")
+		outDir, _ := cmd.Flags().GetString("out-dir")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if by != "category" {
+			return usageError("unsupported --by value %q (only \"category\" is supported)", by)
+		}
+
+		groups := make(map[string][]*link.Link)
+		for _, l := range store.List() {
+			name := categoryFileName(l.Category)
+			groups[name] = append(groups[name], l)
+		}
+
+		if len(groups) == 0 {
+			fmt.Println("No links found.")
+			return nil
+		}
+
+		names := make([]string, 0, len(groups))
+		for name := range groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// Check every file up front so a conflict doesn't leave a partial split
+		if !force {
+			for _, name := range names {
+				path := filepath.Join(outDir, name)
+				if _, err := os.Stat(path); err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+				}
+			}
+		}
+
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			path := filepath.Join(outDir, name)
+			if err := storage.WriteFile(path, groups[name], storagePretty()); err != nil {
+				return fmt.Errorf("writing %s: %w", path, err)
+			}
+			fmt.Printf("%-30s %d links\n", path, len(groups[name]))
+		}
+		return nil
+	},
+}
+
+// categoryFileName returns a safe file name for a category's links. Categories
+// are matched case-insensitively, like the homepage groups them.
+func categoryFileName(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		return "uncategorized.json"
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, category)
+	return name + ".json"
+}
+
+func init() {
+	splitCmd.Flags().String("by", "category", "Field to split links by (category)")
+	splitCmd.Flags().String("out-dir", ".", "Directory to write the files to")
+	splitCmd.Flags().Bool("force", false, "Overwrite existing files")
+
+	rootCmd.AddCommand(splitCmd)
+}
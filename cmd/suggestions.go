@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// Suggestions command
+var suggestionsCmd = &cobra.Command{
+	Use:   "suggestions",
+	Short: "Show the most requested go links that don't exist yet",
+	Long: `Query a running golink server for aliases that were requested but
+not found. Counts are kept in memory and reset when the server restarts.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverURL, _ := cmd.Flags().GetString("server")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		var entries []server.NotFoundEntry
+		if err := getJSON(serverURL, fmt.Sprintf("/api/suggestions?limit=%d", limit), &entries); err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No missing links requested.")
+			return nil
+		}
+
+		fmt.Println("Missing Go Links:")
+		fmt.Println("=================")
+		for _, e := range entries {
+			fmt.Printf("%-15s %5d requests (last %s)\n", e.Alias, e.Count, e.LastSeen.Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	suggestionsCmd.Flags().String("server", defaultServerURL, "URL of the running golink server")
+	suggestionsCmd.Flags().IntP("limit", "n", 20, "Maximum number of aliases to show")
+
+	rootCmd.AddCommand(suggestionsCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync links.json with a git remote",
+	Long: `Commit changes to links.json and pull/push them to a git remote.
+
+The storage directory must be a git repository. The remote defaults to
+"origin" and can be changed with --remote or the sync_remote config
+setting. If both the local and remote catalogs have new commits, the
+local commits are rebased onto the remote; if that conflicts, sync stops
+and leaves the repository untouched so the conflict can be resolved by
+hand.`,
+	Annotations: map[string]string{writesStoreAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pullOnly, _ := cmd.Flags().GetBool("pull")
+		message, _ := cmd.Flags().GetString("message")
+		remote, _ := cmd.Flags().GetString("remote")
+		if remote == "" {
+			remote = viper.GetString("sync_remote")
+		}
+		if remote == "" {
+			remote = "origin"
+		}
+
+		if _, err := git("rev-parse", "--show-toplevel"); err != nil {
+			return fmt.Errorf("%s is not a git repository (run 'git init' there first)", storageDir)
+		}
+
+		branch, err := git("symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return err
+		}
+
+		linksFile := filepath.Base(store.Path())
+		if !pullOnly {
+			status, err := git("status", "--porcelain", "--", linksFile)
+			if err != nil {
+				return err
+			}
+			if status != "" {
+				if _, err := git("add", "--", linksFile); err != nil {
+					return err
+				}
+				if _, err := git("commit", "-m", message, "--", linksFile); err != nil {
+					return err
+				}
+				fmt.Println("Committed local changes.")
+			}
+		}
+
+		if _, err := git("fetch", remote); err != nil {
+			return err
+		}
+
+		remoteBranch := remote + "/" + branch
+		_, remoteErr := git("rev-parse", "--verify", "-q", "refs/remotes/"+remoteBranch)
+		_, localErr := git("rev-parse", "--verify", "-q", "HEAD")
+
+		switch {
+		case remoteErr != nil && localErr != nil:
+			fmt.Println("Nothing to sync yet.")
+			return nil
+		case remoteErr != nil:
+			// The branch doesn't exist on the remote yet
+			if pullOnly {
+				fmt.Printf("Nothing to pull: %s does not exist.\n", remoteBranch)
+				return nil
+			}
+			if _, err := git("push", "-u", remote, branch); err != nil {
+				return err
+			}
+			fmt.Printf("Pushed %s to %s.\n", branch, remote)
+			return nil
+		case localErr != nil:
+			// Nothing committed locally yet, so take the remote catalog as-is
+			if _, err := git("merge", "--ff-only", remoteBranch); err != nil {
+				return err
+			}
+			fmt.Printf("Pulled %s.\n", remoteBranch)
+			return nil
+		}
+
+		ahead, behind, err := divergence(remoteBranch)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case behind > 0 && ahead > 0:
+			if pullOnly {
+				return fmt.Errorf("local and %s have diverged (%d local, %d remote commits); run sync without --pull to rebase", remoteBranch, ahead, behind)
+			}
+			if _, err := git("rebase", remoteBranch); err != nil {
+				git("rebase", "--abort")
+				return fmt.Errorf("%s moved and conflicts with local changes (%d local, %d remote commits); resolve manually in %s", remoteBranch, ahead, behind, storageDir)
+			}
+			fmt.Printf("Rebased %d local commits onto %d remote commits.\n", ahead, behind)
+		case behind > 0:
+			if _, err := git("merge", "--ff-only", remoteBranch); err != nil {
+				return fmt.Errorf("could not fast-forward to %s: %v", remoteBranch, err)
+			}
+			fmt.Printf("Pulled %d commits from %s.\n", behind, remoteBranch)
+		}
+
+		if pullOnly || ahead == 0 {
+			if behind == 0 {
+				fmt.Println("Already up to date.")
+			}
+			return nil
+		}
+
+		if _, err := git("push", remote, "HEAD:"+branch); err != nil {
+			return err
+		}
+		fmt.Printf("Pushed %d commits to %s.\n", ahead, remoteBranch)
+		return nil
+	},
+}
+
+// git runs a git command in the storage directory and returns its trimmed output
+func git(args ...string) (string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = storageDir
+
+	out, err := gitCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// divergence reports how many commits HEAD and the given ref have that the other doesn't
+func divergence(ref string) (ahead, behind int, err error) {
+	out, err := git("rev-list", "--left-right", "--count", "HEAD..."+ref)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, err
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+func init() {
+	syncCmd.Flags().Bool("pull", false, "Only pull remote changes; don't commit or push")
+	syncCmd.Flags().String("remote", "", "Git remote to sync with (default from sync_remote config, or \"origin\")")
+	syncCmd.Flags().StringP("message", "m", "Update go links", "Commit message for local changes")
+
+	rootCmd.AddCommand(syncCmd)
+}
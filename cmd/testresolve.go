@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Test resolve command
+var testResolveCmd = &cobra.Command{
+	Use:   "test-resolve [alias-or-path]",
+	Short: "Show where the server would redirect a request, without serving it",
+	Long: `Resolve a request path the way golink serve does and print the final
+URL and the rules that led there: the link or pattern link that matched,
+alias: references, the schedule rule or target chosen, and placeholders
+filled from the query string. Nothing is opened and no server is started.
+
+The server's settings come from the config file (strict_params,
+redirect_schemes, allowed_redirect_hosts, timezone), so targets the server
+would refuse are reported as errors. Links with several targets use the
+first, where the server takes them in turn.
+
+Examples:
+  golink test-resolve bug/123
+  golink test-resolve '/search?q=golang'
+  golink test-resolve standup --at "2026-10-15 09:30"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		params, _ := cmd.Flags().GetStringToString("param")
+		strictParams, _ := cmd.Flags().GetBool("strict-params")
+		at, _ := cmd.Flags().GetString("at")
+
+		// Split off the query string like the server's router would
+		rawPath, rawQuery, _ := strings.Cut(args[0], "?")
+		path, err := url.PathUnescape(rawPath)
+		if err != nil {
+			return usageError("invalid path %q: %v", rawPath, err)
+		}
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return usageError("invalid query string %q: %v", rawQuery, err)
+		}
+		values := make(map[string]string)
+		for name, v := range query {
+			values[name] = v[0]
+		}
+		for name, value := range params {
+			values[name] = value
+		}
+
+		loc, err := scheduleLocation()
+		if err != nil {
+			return err
+		}
+		t, err := parseAt(at, loc)
+		if err != nil {
+			return usageError("--at: %v", err)
+		}
+
+		ctx, err := resolveContext(values, strictParams, t)
+		if err != nil {
+			return err
+		}
+		res, err := server.Resolve(path, ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s -> %s\n", strings.TrimPrefix(path, "/"), res.URL)
+		fmt.Printf("Rule: %s\n", res.Rule)
+		return nil
+	},
+}
+
+// resolveContext is the context the server would resolve a request in,
+// from the config file's settings, with the given placeholder values and
+// schedules evaluated at t. Links with several targets use the first.
+func resolveContext(params map[string]string, strictParams bool, t time.Time) (server.ResolveContext, error) {
+	allowedHosts, err := server.ParseHostAllowlist(configList("allowed_redirect_hosts"))
+	if err != nil {
+		return server.ResolveContext{}, fmt.Errorf("allowed_redirect_hosts: %v", err)
+	}
+	return server.ResolveContext{
+		Storage:      store,
+		Params:       params,
+		StrictParams: strictParams || viper.GetBool("strict_params"),
+		AliasSpace:   aliasSpace(),
+
+		Time: t,
+
+		RedirectSchemes:      configList("redirect_schemes"),
+		AllowedRedirectHosts: allowedHosts,
+	}, nil
+}
+
+// parseAt parses the --at time, "YYYY-MM-DD HH:MM" or "HH:MM" for today, in
+// loc. An empty value means now.
+func parseAt(value string, loc *time.Location) (time.Time, error) {
+	now := link.Now().In(loc)
+	if value == "" {
+		return now, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, loc); err == nil {
+		return t, nil
+	}
+	clock, err := time.ParseInLocation("15:04", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD HH:MM or HH:MM)", value)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, loc), nil
+}
+
+func init() {
+	testResolveCmd.Flags().StringToString("param", nil, "Value for a {name} placeholder, overriding the query string (key=value, repeatable)")
+	testResolveCmd.Flags().Bool("strict-params", false, "Fail if the final URL has placeholders without a value")
+	testResolveCmd.Flags().String("at", "", "Evaluate schedules at this time instead of now (YYYY-MM-DD HH:MM or HH:MM, in the timezone config)")
+
+	rootCmd.AddCommand(testResolveCmd)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// Top command
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show a live view of a running server's traffic",
+	Long: `Poll a running golink server and show its most used links, recently
+requested missing aliases and request rate, refreshing in place. Counts
+are kept in memory by the server and reset when it restarts; if the server
+goes away, top keeps retrying until it is back.
+
+Press q or Ctrl+C to quit.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serverURL, _ := cmd.Flags().GetString("server")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if interval <= 0 {
+			return usageError("--interval must be positive")
+		}
+
+		restore := rawTerminal()
+		defer restore()
+
+		// Use the alternate screen so quitting leaves the terminal as it was
+		fmt.Print("\033[?1049h\033[?25l")
+		defer fmt.Print("\033[?25h\033[?1049l")
+
+		quit := make(chan struct{})
+		go watchQuitKey(os.Stdin, quit)
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(stop)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var prev *server.Stats
+		var prevAt time.Time
+		for {
+			var stats server.Stats
+			err := getJSON(serverURL, fmt.Sprintf("/api/stats?limit=%d", limit), &stats)
+			polledAt := time.Now()
+
+			rate := -1.0
+			if err == nil {
+				// A new start time means the server restarted and its counts reset
+				if prev != nil && prev.Started.Equal(stats.Started) && stats.Requests >= prev.Requests {
+					rate = float64(stats.Requests-prev.Requests) / polledAt.Sub(prevAt).Seconds()
+				}
+				prev, prevAt = &stats, polledAt
+			} else {
+				prev = nil
+			}
+
+			var screen strings.Builder
+			renderTop(&screen, serverURL, &stats, rate, err)
+			fmt.Print("\033[H\033[2J" + screen.String())
+
+			select {
+			case <-quit:
+				return nil
+			case <-stop:
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// renderTop writes one frame of the top view
+func renderTop(w io.Writer, serverURL string, stats *server.Stats, rate float64, err error) {
+	fmt.Fprintf(w, "golink top - %s (q to quit)\r\n\r\n", serverURL)
+
+	if err != nil {
+		fmt.Fprintf(w, "Waiting for server: %v\r\n", err)
+		return
+	}
+
+	rateText := "-"
+	if rate >= 0 {
+		rateText = fmt.Sprintf("%.1f/s", rate)
+	}
+	uptime := time.Since(stats.Started).Round(time.Second)
+	fmt.Fprintf(w, "Up %s   Requests %d (%s)   Redirects %d\r\n\r\n", uptime, stats.Requests, rateText, stats.Redirects)
+
+	fmt.Fprintf(w, "%-30s %8s\r\n", "TOP LINKS", "HITS")
+	if len(stats.TopLinks) == 0 {
+		fmt.Fprintf(w, "(no redirects yet)\r\n")
+	}
+	for _, l := range stats.TopLinks {
+		fmt.Fprintf(w, "%-30s %8d\r\n", l.Alias, l.Hits)
+	}
+
+	fmt.Fprintf(w, "\r\n%-30s %8s  %s\r\n", "RECENT NOT FOUND", "COUNT", "LAST SEEN")
+	if len(stats.RecentNotFound) == 0 {
+		fmt.Fprintf(w, "(none)\r\n")
+	}
+	for _, e := range stats.RecentNotFound {
+		fmt.Fprintf(w, "%-30s %8d  %s\r\n", e.Alias, e.Count, e.LastSeen.Local().Format("15:04:05"))
+	}
+}
+
+// rawTerminal turns off line buffering and echo on the controlling terminal
+// so single key presses can be read, and returns a function that restores
+// the previous settings. It does nothing when stdin isn't a terminal or stty
+// isn't available.
+func rawTerminal() func() {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return func() {}
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return func() {}
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}
+}
+
+// stty runs stty against the terminal on stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// watchQuitKey closes quit when q is pressed. If input ends first, top keeps
+// running until it is interrupted.
+func watchQuitKey(r io.Reader, quit chan<- struct{}) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			return
+		}
+		if buf[0] == 'q' || buf[0] == 'Q' {
+			close(quit)
+			return
+		}
+	}
+}
+
+func init() {
+	topCmd.Flags().String("server", defaultServerURL, "URL of the running golink server")
+	topCmd.Flags().Duration("interval", 2*time.Second, "How often to refresh")
+	topCmd.Flags().IntP("limit", "n", 10, "Number of links and missing aliases to show")
+
+	rootCmd.AddCommand(topCmd)
+}
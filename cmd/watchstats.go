@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// requestLogPattern matches a request line written by the server's log
+// middleware: date, time, client IP, method, request URI, status, duration,
+// and the chosen target for links with several
+var requestLogPattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) (\S+) (\S+) (\S+) (\d{3}) (\S+)(?: -> (\S+))?$`)
+
+// requestLogEntry is a parsed request log line
+type requestLogEntry struct {
+	Time     string
+	Client   string
+	Method   string
+	URI      string
+	Status   string
+	Duration string
+	Target   string
+}
+
+// parseRequestLog parses a server request log line, reporting false for
+// other log output
+func parseRequestLog(line string) (requestLogEntry, bool) {
+	m := requestLogPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return requestLogEntry{}, false
+	}
+	return requestLogEntry{Time: m[1], Client: m[2], Method: m[3], URI: m[4], Status: m[5], Duration: m[6], Target: m[7]}, true
+}
+
+// Watch stats command
+var watchStatsCmd = &cobra.Command{
+	Use:   "watch-stats",
+	Short: "Follow a server log and print each go link request",
+	Long: `Follow the log written by golink serve (e.g. started with
+"golink serve 2>> golink.log") and print each go link request as it
+happens, with its target, status and client. Requests for the server's
+own pages are skipped. Targets and categories come from the local links.
+
+The file is reopened if it is replaced or truncated, so log rotation is
+handled. Press Ctrl+C to stop.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		aliasFilter, _ := cmd.Flags().GetString("alias")
+		aliasFilter = normalizeAlias(aliasFilter)
+		categoryFilter, _ := cmd.Flags().GetString("category")
+		fromStart, _ := cmd.Flags().GetBool("from-start")
+
+		if path == "" {
+			return usageError("--file is required")
+		}
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+		err := followFile(path, fromStart, stop, func(line string) {
+			entry, ok := parseRequestLog(line)
+			if !ok {
+				return
+			}
+
+			u, err := url.ParseRequestURI(entry.URI)
+			if err != nil {
+				return
+			}
+			alias := normalizeAlias(strings.TrimPrefix(u.Path, "/"))
+			if alias == "" || server.IsReserved(alias) || alias == "favicon.ico" {
+				return
+			}
+			if aliasFilter != "" && alias != aliasFilter {
+				return
+			}
+
+			l, err := store.Get(alias)
+			if categoryFilter != "" && (err != nil || !strings.EqualFold(l.Category, categoryFilter)) {
+				return
+			}
+
+			target := "(not found)"
+			if entry.Target != "" {
+				target = entry.Target
+			} else if err == nil {
+				target = l.URL
+			}
+			fmt.Printf("%s  %-15s -> %s  %s  %s\n", entry.Time, alias, target, entry.Status, entry.Client)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return nil
+	},
+}
+
+// followFile calls fn with each complete line appended to the file at path
+// until stop receives, like tail -F. It starts at the end of the file unless
+// fromStart is set, and reopens the file from the beginning when it is
+// replaced (rotated) or truncated.
+func followFile(path string, fromStart bool, stop <-chan os.Signal, fn func(line string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	if !fromStart {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+
+	reader := bufio.NewReader(f)
+	var partial string
+	for {
+		line, err := reader.ReadString('\n')
+		partial += line
+		if err == nil {
+			fn(partial)
+			partial = ""
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		// Nothing new yet: wait, then check whether the file was rotated
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		current, statErr := f.Stat()
+		latest, pathErr := os.Stat(path)
+		if pathErr != nil {
+			// Mid-rotation; try again once the new file exists
+			continue
+		}
+
+		offset, _ := f.Seek(0, io.SeekCurrent)
+		switch {
+		case statErr != nil || !os.SameFile(current, latest):
+			newFile, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f = newFile
+		case latest.Size() < offset:
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		reader.Reset(f)
+		partial = ""
+	}
+}
+
+func init() {
+	watchStatsCmd.Flags().StringP("file", "f", "", "Server log file to follow")
+	watchStatsCmd.Flags().String("alias", "", "Only show requests for this alias")
+	watchStatsCmd.Flags().StringP("category", "c", "", "Only show requests for links in this category")
+	watchStatsCmd.Flags().Bool("from-start", false, "Print the existing log before following it")
+
+	watchStatsCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	watchStatsCmd.RegisterFlagCompletionFunc("alias", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeAliases(cmd, nil, toComplete)
+	})
+
+	rootCmd.AddCommand(watchStatsCmd)
+}
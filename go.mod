@@ -2,7 +2,11 @@ module github.com/bkarpinos/golink
 
 go 1.24.0
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/spf13/cast v1.6.0
+	github.com/spf13/cobra v1.9.1
+	github.com/spf13/viper v1.19.0
+)
 
 require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
@@ -13,8 +17,6 @@ require (
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
 	github.com/spf13/afero v1.11.0 // indirect
-	github.com/spf13/cast v1.6.0 // indirect
-	github.com/spf13/viper v1.19.0 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.9.0 // indirect
@@ -27,6 +29,6 @@ require (
 require (
 	github.com/fsnotify/fsnotify v1.8.0
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
+	github.com/spf13/pflag v1.0.6
 	golang.org/x/sys v0.18.0 // indirect
 )
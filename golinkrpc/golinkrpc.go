@@ -0,0 +1,163 @@
+// Package golinkrpc is a Go client for the JSON-RPC service started by
+// "golink serve --rpc-port", and defines the messages the service exchanges.
+//
+// The service speaks JSON-RPC 1.0 over TCP (as implemented by
+// net/rpc/jsonrpc), so clients in other languages can call the same
+// methods: Links.Get, Links.List, Links.Create, Links.Update, Links.Delete
+// and Links.Resolve, each taking one of the Args types below.
+package golinkrpc
+
+import (
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+)
+
+// ServiceName is the name the service's methods are registered under
+const ServiceName = "Links"
+
+// Link is a go link as stored by golink
+type Link struct {
+	Alias        string         `json:"alias"`
+	URL          string         `json:"url"`
+	Targets      []string       `json:"targets,omitempty"`
+	TargetPolicy string         `json:"target_policy,omitempty"`
+	Description  string         `json:"description,omitempty"`
+	Category     string         `json:"category,omitempty"`
+	Redirect     string         `json:"redirect,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Schedule     []ScheduleRule `json:"schedule,omitempty"`
+	Pattern      string         `json:"pattern,omitempty"` // Regular expression matched against requested aliases; groups fill $1 in URL
+}
+
+// ScheduleRule sends a link to another URL during a time window
+type ScheduleRule struct {
+	URL   string   `json:"url"`
+	Days  []string `json:"days,omitempty"`
+	From  string   `json:"from,omitempty"`
+	Until string   `json:"until,omitempty"`
+	Start string   `json:"start,omitempty"`
+	End   string   `json:"end,omitempty"`
+}
+
+// GetArgs are the arguments to Links.Get, which replies with a Link
+type GetArgs struct {
+	Alias string `json:"alias"`
+}
+
+// ListArgs are the arguments to Links.List, which replies with a ListReply
+type ListArgs struct {
+	Category string `json:"category,omitempty"` // Only list links in this category
+}
+
+// ListReply is the reply to Links.List
+type ListReply struct {
+	Links []Link `json:"links"`
+}
+
+// WriteArgs are the arguments to Links.Create and Links.Update, which reply
+// with the stored Link. Token is the server's admin token, required unless
+// the client connects from the server's own machine.
+type WriteArgs struct {
+	Token string `json:"token,omitempty"`
+	Link  Link   `json:"link"`
+}
+
+// DeleteArgs are the arguments to Links.Delete, which replies with the
+// deleted Link
+type DeleteArgs struct {
+	Token string `json:"token,omitempty"`
+	Alias string `json:"alias"`
+}
+
+// ResolveArgs are the arguments to Links.Resolve, which replies with a
+// ResolveReply
+type ResolveArgs struct {
+	Alias  string            `json:"alias"`
+	Params map[string]string `json:"params,omitempty"` // Values for {name} placeholders
+	Strict bool              `json:"strict,omitempty"` // Fail if placeholders are left unfilled
+}
+
+// ResolveReply is where a link ends up
+type ResolveReply struct {
+	Hops []string `json:"hops"` // Links followed, starting with the requested one or the pattern link it matched
+	URL  string   `json:"url"`
+}
+
+// Client calls a golink JSON-RPC service. Errors returned by the service
+// are rpc.ServerError values carrying its message.
+type Client struct {
+	rpc   *rpc.Client
+	Token string // Admin token sent with Create, Update and Delete
+}
+
+// Dial connects to the service at addr, e.g. "localhost:8081"
+func Dial(addr string) (*Client, error) {
+	c, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rpc: c}, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// Get returns the link with the given alias
+func (c *Client) Get(alias string) (*Link, error) {
+	var l Link
+	if err := c.rpc.Call(ServiceName+".Get", GetArgs{Alias: alias}, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// List returns every link, or only those in category if it isn't empty,
+// sorted by alias
+func (c *Client) List(category string) ([]Link, error) {
+	var reply ListReply
+	if err := c.rpc.Call(ServiceName+".List", ListArgs{Category: category}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Links, nil
+}
+
+// Create adds a link and returns it as stored
+func (c *Client) Create(l Link) (*Link, error) {
+	var stored Link
+	if err := c.rpc.Call(ServiceName+".Create", WriteArgs{Token: c.Token, Link: l}, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Update replaces an existing link and returns it as stored
+func (c *Client) Update(l Link) (*Link, error) {
+	var stored Link
+	if err := c.rpc.Call(ServiceName+".Update", WriteArgs{Token: c.Token, Link: l}, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Delete removes the link with the given alias and returns it
+func (c *Client) Delete(alias string) (*Link, error) {
+	var deleted Link
+	if err := c.rpc.Call(ServiceName+".Delete", DeleteArgs{Token: c.Token, Alias: alias}, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// Resolve follows a link's alias references and returns its final URL with
+// placeholders filled from params
+func (c *Client) Resolve(alias string, params map[string]string, strict bool) (*ResolveReply, error) {
+	var reply ResolveReply
+	if err := c.rpc.Call(ServiceName+".Resolve", ResolveArgs{Alias: alias, Params: params, Strict: strict}, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
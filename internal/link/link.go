@@ -1,28 +1,384 @@
 package link
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 )
 
+// now returns the current time. It is a variable so tests can freeze the clock.
+var now = time.Now
+
+// Now returns the current time from the package clock, for time-based
+// decisions about links made elsewhere, such as applying schedules
+func Now() time.Time {
+	return now()
+}
+
 // Link represents a go link with alias and target URL
 type Link struct {
-	Alias       string    `json:"alias"`
-	URL         string    `json:"url"`
-	Description string    `json:"description,omitempty"`
-	Category    string    `json:"category,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Alias        string    `json:"alias"`
+	URL          string    `json:"url"`
+	Targets      []string  `json:"targets,omitempty"`       // Several URLs to spread redirects over; URL is the first
+	TargetPolicy string    `json:"target_policy,omitempty"` // How a target is picked: PolicyRoundRobin (default) or PolicyRandom
+	Description  string    `json:"description,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	Redirect     string    `json:"redirect,omitempty"` // RedirectFound, RedirectBeacon, or empty for the server default
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Schedule []ScheduleRule `json:"schedule,omitempty"` // Checked in order before the targets; the first matching rule wins
+
+	// Pattern makes this a pattern link: a regular expression matched
+	// against requested aliases that have no link of their own, whose
+	// groups fill $1 or ${name} in the URL. The alias only names the link.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Redirect modes a link or server can use
+const (
+	RedirectFound  = "302"    // Plain 302 redirect
+	RedirectBeacon = "beacon" // Small page that fires an analytics beacon, then redirects
+)
+
+// ValidateRedirect checks that mode is a known redirect mode, or empty
+func ValidateRedirect(mode string) error {
+	switch mode {
+	case "", RedirectFound, RedirectBeacon:
+		return nil
+	}
+	return fmt.Errorf("unknown redirect mode %q (use %s or %s)", mode, RedirectFound, RedirectBeacon)
+}
+
+// Target policies for links with several targets
+const (
+	PolicyRoundRobin = "round-robin"
+	PolicyRandom     = "random"
+)
+
+// ValidateTargetPolicy checks that policy is a known target policy, or empty
+func ValidateTargetPolicy(policy string) error {
+	switch policy {
+	case "", PolicyRoundRobin, PolicyRandom:
+		return nil
+	}
+	return fmt.Errorf("unknown target policy %q (use %s or %s)", policy, PolicyRoundRobin, PolicyRandom)
 }
 
 // NewLink creates a new link with current timestamp
 func NewLink(alias, url, description, category string) *Link {
-	now := time.Now()
+	t := now()
 	return &Link{
 		Alias:       alias,
 		URL:         url,
 		Description: description,
 		Category:    category,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		CreatedAt:   t,
+		UpdatedAt:   t,
+	}
+}
+
+// Clone returns a copy of the link that can be changed without affecting
+// the original
+func (l *Link) Clone() *Link {
+	c := *l
+	c.Targets = slices.Clone(l.Targets)
+	if l.Schedule != nil {
+		c.Schedule = make([]ScheduleRule, len(l.Schedule))
+		for i, rule := range l.Schedule {
+			rule.Days = slices.Clone(rule.Days)
+			c.Schedule[i] = rule
+		}
+	}
+	return &c
+}
+
+// Touch sets the link's UpdatedAt timestamp to the current time
+func (l *Link) Touch() {
+	l.UpdatedAt = now()
+}
+
+// NeverUpdated reports whether the link hasn't been changed since it was
+// created
+func (l *Link) NeverUpdated() bool {
+	return l.UpdatedAt.Equal(l.CreatedAt)
+}
+
+// NormalizeAlias returns the canonical form of an alias typed with spaces,
+// replacing each run of whitespace with replacement and trimming the ends, so
+// "team meeting" becomes "team-meeting". An empty replacement leaves the
+// alias unchanged.
+func NormalizeAlias(alias, replacement string) string {
+	if replacement == "" || !strings.ContainsFunc(alias, unicode.IsSpace) {
+		return alias
+	}
+	return strings.Join(strings.Fields(alias), replacement)
+}
+
+// ValidateAlias checks that an alias can be stored and reached as a URL path.
+// Aliases may contain letters, digits, dashes, underscores, dots and '/'.
+// Dots are allowed anywhere in an alias (e.g. "k8s.dev", "v1.2" or
+// "report.pdf"), but "." and ".." segments are rejected because HTTP path
+// cleaning would rewrite them before they reach the server.
+func ValidateAlias(alias string) error {
+	if alias == "" {
+		return errors.New("alias must not be empty")
+	}
+	if strings.HasPrefix(alias, "/") || strings.HasSuffix(alias, "/") {
+		return fmt.Errorf("alias %q must not start or end with '/'", alias)
+	}
+	for _, r := range alias {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_./", r) {
+			return fmt.Errorf("alias %q contains invalid character %q (use letters, digits, '-', '_', '.' and '/')", alias, r)
+		}
+	}
+	for _, segment := range strings.Split(alias, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("alias %q contains an empty, '.' or '..' path segment", alias)
+		}
+	}
+	return nil
+}
+
+// URLs returns the link's targets: Targets when set, otherwise just URL
+func (l *Link) URLs() []string {
+	if len(l.Targets) > 0 {
+		return l.Targets
+	}
+	return []string{l.URL}
+}
+
+// allURLs returns the link's targets followed by the URLs of its schedule
+// rules, which are all checked the same way
+func (l *Link) allURLs() []string {
+	if len(l.Schedule) == 0 {
+		return l.URLs()
+	}
+	urls := slices.Clone(l.URLs())
+	for _, rule := range l.Schedule {
+		urls = append(urls, rule.URL)
+	}
+	return urls
+}
+
+// Scheme returns the lowercased scheme of the link's URL, or "" if it has
+// none or can't be parsed
+func (l *Link) Scheme() string {
+	return URLScheme(l.URL)
+}
+
+// IsWeb reports whether every target of the link, scheduled ones included,
+// is an http or https URL, which browsers can be redirected to and the
+// server can fetch
+func (l *Link) IsWeb() bool {
+	for _, target := range l.allURLs() {
+		if scheme := URLScheme(target); scheme != "http" && scheme != "https" {
+			return false
+		}
+	}
+	return true
+}
+
+// AliasPrefix marks a target that points at another link rather than a URL,
+// e.g. "alias:meeting", so the referring link follows it when it changes
+const AliasPrefix = "alias:"
+
+// MaxAliasDepth is how many alias: references are followed before giving up,
+// which also stops reference loops
+const MaxAliasDepth = 8
+
+var (
+	// ErrAliasLoop is returned when alias: references go deeper than MaxAliasDepth
+	ErrAliasLoop = errors.New("too many alias references (is there a loop?)")
+
+	// ErrMissingAlias is returned when an alias: reference names a link that doesn't exist
+	ErrMissingAlias = errors.New("refers to a missing alias")
+)
+
+// AliasRef returns the alias an "alias:" target refers to
+func AliasRef(target string) (string, bool) {
+	if !strings.HasPrefix(target, AliasPrefix) {
+		return "", false
+	}
+	return target[len(AliasPrefix):], true
+}
+
+// FollowAliases follows alias: references from l until it reaches a real
+// URL. pick chooses which of a link's targets to use and lookup finds links
+// by alias. It returns the link that owns the final URL, so its parameters
+// and settings apply, and the URL itself.
+func FollowAliases(l *Link, pick func(*Link) string, lookup func(string) (*Link, bool)) (*Link, string, error) {
+	target := pick(l)
+	for depth := 0; ; depth++ {
+		ref, ok := AliasRef(target)
+		if !ok {
+			return l, target, nil
+		}
+		if depth == MaxAliasDepth {
+			return nil, "", fmt.Errorf("%s: %w", l.Alias, ErrAliasLoop)
+		}
+
+		next, ok := lookup(ref)
+		if !ok {
+			return nil, "", fmt.Errorf("%s %w %s", l.Alias, ErrMissingAlias, ref)
+		}
+		l, target = next, pick(next)
+	}
+}
+
+// WithFragment returns the URL raw with its fragment replaced by fragment,
+// keeping any query string, so "https://a.example.com/doc?v=2#old" becomes
+// "https://a.example.com/doc?v=2#new". An empty fragment leaves raw as is.
+func WithFragment(raw, fragment string) string {
+	fragment = strings.TrimPrefix(fragment, "#")
+	if fragment == "" {
+		return raw
+	}
+	base, _, _ := strings.Cut(raw, "#")
+	return base + "#" + (&url.URL{Fragment: fragment}).EscapedFragment()
+}
+
+// URLScheme returns the lowercased scheme of a link URL, which may contain
+// {name} placeholders, or "" if it has none or can't be parsed
+func URLScheme(raw string) string {
+	// Most links are plain web URLs; recognize them without parsing
+	if len(raw) >= 8 && strings.EqualFold(raw[:8], "https://") {
+		return "https"
+	}
+	if len(raw) >= 7 && strings.EqualFold(raw[:7], "http://") {
+		return "http"
+	}
+
+	u, err := parseTemplate(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme)
+}
+
+// ValidateURL checks that each of the link's URLs is absolute and uses http,
+// https or one of allowedSchemes (e.g. "slack", "vscode" or "file"), that its
+// target policy is known and that its schedule rules are well formed
+func (l *Link) ValidateURL(allowedSchemes []string) error {
+	for _, target := range l.URLs() {
+		if err := validateTarget(target, allowedSchemes); err != nil {
+			return err
+		}
+	}
+	for _, rule := range l.Schedule {
+		if err := rule.Validate(allowedSchemes); err != nil {
+			return err
+		}
+	}
+	return ValidateTargetPolicy(l.TargetPolicy)
+}
+
+// validateTarget checks a single target URL for ValidateURL
+func validateTarget(raw string, allowedSchemes []string) error {
+	if raw == "" {
+		return errors.New("url must not be empty")
+	}
+	if ref, ok := AliasRef(raw); ok {
+		return ValidateAlias(ref)
+	}
+
+	u, err := parseTemplate(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", raw, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme == "":
+		return fmt.Errorf("url %q is not absolute (add a scheme such as https://)", raw)
+	case scheme == "http" || scheme == "https":
+		if u.Host == "" {
+			return fmt.Errorf("url %q has no host", raw)
+		}
+	case !slices.ContainsFunc(allowedSchemes, func(s string) bool { return strings.EqualFold(s, scheme) }):
+		return fmt.Errorf("url scheme %q is not allowed (add it to link_schemes)", scheme)
+	}
+	return nil
+}
+
+// parseTemplate parses a link URL with any {name} placeholders filled in, so
+// templates like https://{team}.example.com parse as real URLs would
+func parseTemplate(raw string) (*url.URL, error) {
+	params := make(map[string]string)
+	for _, m := range paramPattern.FindAllStringSubmatch(raw, -1) {
+		params[m[1]] = "x"
+	}
+	expanded, _ := expand(raw, params, false, "")
+	return url.Parse(expanded)
+}
+
+// paramPattern matches named parameter placeholders like {q} in a link's URL
+var paramPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Params returns the names of the parameter placeholders in the link's URLs,
+// scheduled ones included
+func (l *Link) Params() []string {
+	var names []string
+	for _, target := range l.allURLs() {
+		if !strings.Contains(target, "{") {
+			continue
+		}
+		for _, m := range paramPattern.FindAllStringSubmatch(target, -1) {
+			if !slices.Contains(names, m[1]) {
+				names = append(names, m[1])
+			}
+		}
+	}
+	return names
+}
+
+// Expand returns the link's URL with its {name} placeholders replaced by the
+// given parameter values. Values are path-escaped before the query string and
+// query-escaped after it. Placeholders without a value are replaced with an
+// empty string, or reported as an error when strict is set.
+func (l *Link) Expand(params map[string]string, strict bool) (string, error) {
+	return l.ExpandURL(l.URL, params, strict)
+}
+
+// ExpandURL is Expand for one of the link's URLs, such as a target picked
+// from Targets
+func (l *Link) ExpandURL(raw string, params map[string]string, strict bool) (string, error) {
+	return expand(raw, params, strict, l.Alias)
+}
+
+// expand fills the {name} placeholders in raw for ExpandURL
+func expand(raw string, params map[string]string, strict bool, alias string) (string, error) {
+	queryStart := strings.IndexAny(raw, "?#")
+
+	var result strings.Builder
+	var missing []string
+	last := 0
+	for _, loc := range paramPattern.FindAllStringSubmatchIndex(raw, -1) {
+		name := raw[loc[2]:loc[3]]
+		value, ok := params[name]
+		if !ok && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+
+		if queryStart >= 0 && loc[0] > queryStart {
+			value = url.QueryEscape(value)
+		} else {
+			value = url.PathEscape(value)
+		}
+
+		result.WriteString(raw[last:loc[0]])
+		result.WriteString(value)
+		last = loc[1]
+	}
+	result.WriteString(raw[last:])
+
+	if strict && len(missing) > 0 {
+		return "", fmt.Errorf("missing parameters for %s: %s", alias, strings.Join(missing, ", "))
 	}
+	return result.String(), nil
 }
@@ -0,0 +1,100 @@
+package link
+
+import "testing"
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		alias string
+		valid bool
+	}{
+		{"docs", true},
+		{"team/roadmap", true},
+		{"my_link-2", true},
+		{"café", true},
+
+		// Dots are allowed anywhere, including what looks like an extension
+		{"k8s.dev", true},
+		{"v1.2", true},
+		{"report.pdf", true},
+		{"team/report.pdf", true},
+		{".well-known", true},
+		{"trailing.", true},
+
+		// Path cleaning would rewrite these segments
+		{".", false},
+		{"..", false},
+		{"a/./b", false},
+		{"a/../b", false},
+		{"a//b", false},
+		{"/docs", false},
+		{"docs/", false},
+
+		{"", false},
+		{"team meeting", false},
+		{"what?", false},
+		{"a#b", false},
+		{"100%", false},
+		{`a\b`, false},
+		{"c++", false},
+		{"me@work", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateAlias(tt.alias); (err == nil) != tt.valid {
+			t.Errorf("ValidateAlias(%q) = %v, want valid %v", tt.alias, err, tt.valid)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		url    string
+		params map[string]string
+		strict bool
+		want   string
+		err    bool
+	}{
+		{"https://example.com/search?q={q}", map[string]string{"q": "golang"}, false, "https://example.com/search?q=golang", false},
+
+		// Values are escaped for the part of the URL they land in
+		{"https://example.com/search?q={q}", map[string]string{"q": "go lang"}, false, "https://example.com/search?q=go+lang", false},
+		{"https://example.com/search?q={q}", map[string]string{"q": "a&b=c"}, false, "https://example.com/search?q=a%26b%3Dc", false},
+		{"https://example.com/search?q={q}", map[string]string{"q": "c#"}, false, "https://example.com/search?q=c%23", false},
+		{"https://example.com/search?q={q}", map[string]string{"q": "café"}, false, "https://example.com/search?q=caf%C3%A9", false},
+		{"https://example.com/wiki/{page}", map[string]string{"page": "go lang"}, false, "https://example.com/wiki/go%20lang", false},
+		{"https://example.com/wiki/{page}", map[string]string{"page": "a&b"}, false, "https://example.com/wiki/a&b", false},
+		{"https://example.com/wiki/{page}", map[string]string{"page": "c#?x"}, false, "https://example.com/wiki/c%23%3Fx", false},
+		{"https://example.com/wiki/{page}", map[string]string{"page": "a/b"}, false, "https://example.com/wiki/a%2Fb", false},
+		{"https://example.com/wiki/{page}", map[string]string{"page": "naïve"}, false, "https://example.com/wiki/na%C3%AFve", false},
+		{"https://example.com/{page}#{section}", map[string]string{"page": "doc", "section": "a b"}, false, "https://example.com/doc#a+b", false},
+
+		// Missing values are left empty, or rejected when strict
+		{"https://example.com/search?q={q}", nil, false, "https://example.com/search?q=", false},
+		{"https://example.com/search?q={q}", nil, true, "", true},
+	}
+	for _, tt := range tests {
+		l := NewLink("test", tt.url, "", "")
+		got, err := l.Expand(tt.params, tt.strict)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("Expand(%s, %v) = %q, %v; want %q, error %v", tt.url, tt.params, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestWithFragment(t *testing.T) {
+	tests := []struct {
+		url, fragment, want string
+	}{
+		{"https://x/a", "new", "https://x/a#new"},
+		{"https://x/a?b=1", "new", "https://x/a?b=1#new"},
+		{"https://x/a#old", "new", "https://x/a#new"},
+		{"https://x/a?b=1#old", "new", "https://x/a?b=1#new"},
+		{"https://x/a?b=1#old", "#new", "https://x/a?b=1#new"},
+		{"https://x/a?b=1#old", "", "https://x/a?b=1#old"},
+		{"https://x/a", "two words", "https://x/a#two%20words"},
+	}
+	for _, tt := range tests {
+		if got := WithFragment(tt.url, tt.fragment); got != tt.want {
+			t.Errorf("WithFragment(%q, %q) = %q, want %q", tt.url, tt.fragment, got, tt.want)
+		}
+	}
+}
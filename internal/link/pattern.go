@@ -0,0 +1,153 @@
+package link
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+)
+
+// Limits on pattern links. Go regular expressions run in time linear in the
+// input, so patterns can't backtrack catastrophically, but every request
+// for a missing alias is matched against every pattern, so large ones are
+// refused.
+const (
+	MaxPatternLength = 256  // Bytes of pattern source
+	maxPatternInsts  = 2000 // Instructions in the compiled pattern
+)
+
+// CompilePattern compiles a pattern link's regular expression, refusing
+// ones too large to evaluate on every request
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("pattern must not be empty")
+	}
+	if len(pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("pattern is longer than %d characters", MaxPatternLength)
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	if len(prog.Inst) > maxPatternInsts {
+		return nil, fmt.Errorf("pattern %q is too complex (use fewer or smaller repetitions)", pattern)
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// ValidatePattern checks a pattern link: its pattern must compile and it
+// must have a single URL target with no schedule. A $ in the URL refers to a
+// capture group and $$ is a literal dollar sign.
+func (l *Link) ValidatePattern() error {
+	re, err := CompilePattern(l.Pattern)
+	if err != nil {
+		return err
+	}
+	if len(l.Targets) > 1 || len(l.Schedule) > 0 {
+		return errors.New("pattern links can't have several targets or a schedule")
+	}
+	if _, ok := AliasRef(l.URL); ok {
+		return errors.New("pattern links can't point at another alias")
+	}
+
+	// Catch references to groups the pattern doesn't have
+	for _, m := range groupRefPattern.FindAllStringSubmatch(l.URL, -1) {
+		name := m[1] + m[2]
+		if name == "$" {
+			continue
+		}
+		if !slices.Contains(re.SubexpNames(), name) && !validGroupNumber(name, re.NumSubexp()) {
+			return fmt.Errorf("url refers to $%s, which the pattern doesn't capture", name)
+		}
+	}
+	return nil
+}
+
+// groupRefPattern matches $1, ${1}, $name and ${name} references in a
+// pattern link's URL, and $$
+var groupRefPattern = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+|\$))`)
+
+// validGroupNumber reports whether name is the number of a capture group
+func validGroupNumber(name string, groups int) bool {
+	n := 0
+	for _, c := range name {
+		if c < '0' || c > '9' {
+			return false
+		}
+		n = n*10 + int(c-'0')
+		if n > groups {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandPattern fills the $ references in a pattern link's URL with the
+// groups re captured from alias, reporting false if re doesn't match.
+// Captured text is escaped so a request can't change where the URL leads:
+// in the path it is path-escaped, keeping slashes, so it can't add a query
+// string or fragment; in the scheme, host, query string and fragment every
+// delimiter is escaped, so it can't end the host early, add user info or
+// add query parameters.
+func ExpandPattern(re *regexp.Regexp, alias, target string) (string, bool) {
+	match := re.FindStringSubmatchIndex(alias)
+	if match == nil {
+		return "", false
+	}
+
+	pathStart := authorityEnd(target)
+	pathEnd := len(target)
+	if i := strings.IndexAny(target[pathStart:], "?#"); i >= 0 {
+		pathEnd = pathStart + i
+	}
+	querySrc, queryMatch := escapeGroups(alias, match, url.QueryEscape)
+	pathSrc, pathMatch := escapeGroups(alias, match, func(s string) string {
+		return strings.ReplaceAll(url.PathEscape(s), "%2F", "/")
+	})
+
+	expanded := re.ExpandString(nil, target[:pathStart], querySrc, queryMatch)
+	expanded = re.ExpandString(expanded, target[pathStart:pathEnd], pathSrc, pathMatch)
+	expanded = re.ExpandString(expanded, target[pathEnd:], querySrc, queryMatch)
+	return string(expanded), true
+}
+
+// authorityEnd returns the index where the path of a URL template begins,
+// after its scheme and host, or 0 if it has no host (like mailto:).
+// References can't span it, or the start of the query string or fragment,
+// since they contain no /, ? or #.
+func authorityEnd(target string) int {
+	i := strings.Index(target, "://")
+	if i < 0 {
+		return 0
+	}
+	if j := strings.IndexAny(target[i+3:], "/?#"); j >= 0 {
+		return i + 3 + j
+	}
+	return len(target)
+}
+
+// escapeGroups returns the groups captured by match from alias, escaped,
+// as a source string and match indices for Regexp.ExpandString
+func escapeGroups(alias string, match []int, escape func(string) string) (string, []int) {
+	escaped := make([]int, len(match))
+	var src strings.Builder
+	for i := 0; i < len(match); i += 2 {
+		if match[i] < 0 {
+			escaped[i], escaped[i+1] = -1, -1
+			continue
+		}
+		escaped[i] = src.Len()
+		src.WriteString(escape(alias[match[i]:match[i+1]]))
+		escaped[i+1] = src.Len()
+	}
+	return src.String(), escaped
+}
@@ -0,0 +1,38 @@
+package link
+
+import "testing"
+
+func TestExpandPattern(t *testing.T) {
+	tests := []struct {
+		pattern, target, alias string
+		want                   string
+		ok                     bool
+	}{
+		{`^bug/(\d+)$`, "https://tracker.example.com/issue/$1", "bug/123", "https://tracker.example.com/issue/123", true},
+		{`^bug/(\d+)$`, "https://tracker.example.com/issue/$1", "bugs", "", false},
+		{`^src/(.+)$`, "https://code.example.com/tree/${1}", "src/a/b.go", "https://code.example.com/tree/a/b.go", true},
+		{`^q/(.+)$`, "https://example.com/$1", "q/x?y=1#z", "https://example.com/x%3Fy=1%23z", true},
+		{`^(?P<team>\w+)/wiki$`, "https://wiki.example.com/${team}/$$", "infra/wiki", "https://wiki.example.com/infra/$", true},
+
+		// Captures in the query string can't add parameters
+		{`^q/(.+)$`, "https://example.com/search?q=$1", "q/go&x=y", "https://example.com/search?q=go%26x%3Dy", true},
+		{`^q/(.+)$`, "https://example.com/search?q=$1#top", "q/a b#c", "https://example.com/search?q=a+b%23c#top", true},
+		{`^doc/(.+)$`, "https://example.com/$1?ref=$1", "doc/a/b&c", "https://example.com/a/b&c?ref=a%2Fb%26c", true},
+
+		// Captures in the host can't leave it
+		{`^(.+)/docs$`, "https://$1.example.com/docs", "evil.com//docs", "https://evil.com%2F.example.com/docs", true},
+		{`^(.+)/docs$`, "https://$1.example.com/docs", "a@evil.com/docs", "https://a%40evil.com.example.com/docs", true},
+		{`^(.+)$`, "https://$1.example.com", "x:1", "https://x%3A1.example.com", true},
+	}
+
+	for _, tt := range tests {
+		re, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompilePattern(%q): %v", tt.pattern, err)
+		}
+		got, ok := ExpandPattern(re, tt.alias, tt.target)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ExpandPattern(%q, %q, %q) = %q, %v; want %q, %v", tt.pattern, tt.alias, tt.target, got, ok, tt.want, tt.ok)
+		}
+	}
+}
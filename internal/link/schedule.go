@@ -0,0 +1,160 @@
+package link
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Layouts for schedule rule times and dates
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+// weekdays maps the day names accepted in schedule rules to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ScheduleRule sends a link to URL during a time window. Every field that is
+// set must match the time of the request; a rule with only URL always
+// matches. Days and dates are those of the request itself, so a window that
+// wraps past midnight on "mon" covers Monday night and early Monday morning.
+type ScheduleRule struct {
+	URL   string   `json:"url"`
+	Days  []string `json:"days,omitempty"`  // Weekdays such as "mon" or "tuesday"
+	From  string   `json:"from,omitempty"`  // Start time of day as "15:04", inclusive
+	Until string   `json:"until,omitempty"` // End time of day as "15:04", exclusive; before From wraps past midnight
+	Start string   `json:"start,omitempty"` // First date as "2006-01-02"
+	End   string   `json:"end,omitempty"`   // Last date as "2006-01-02", inclusive
+}
+
+// Validate checks the rule's URL like ValidateURL does, and that its days,
+// times and dates are well formed
+func (r ScheduleRule) Validate(allowedSchemes []string) error {
+	if err := validateTarget(r.URL, allowedSchemes); err != nil {
+		return err
+	}
+	for _, day := range r.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("unknown day %q (use mon, tue, wed, thu, fri, sat or sun)", day)
+		}
+	}
+	for _, clock := range []string{r.From, r.Until} {
+		if err := checkLayout(clock, clockLayout, "HH:MM"); err != nil {
+			return err
+		}
+	}
+	if r.From != "" && r.From == r.Until {
+		return fmt.Errorf("schedule window %s-%s is empty", r.From, r.Until)
+	}
+	for _, date := range []string{r.Start, r.End} {
+		if err := checkLayout(date, dateLayout, "YYYY-MM-DD"); err != nil {
+			return err
+		}
+	}
+	if r.Start != "" && r.End != "" && r.End < r.Start {
+		return fmt.Errorf("schedule ends (%s) before it starts (%s)", r.End, r.Start)
+	}
+	return nil
+}
+
+// checkLayout checks that value is empty or exactly in layout. Values must
+// be zero-padded so rules can compare them as strings.
+func checkLayout(value, layout, name string) error {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil || t.Format(layout) != value {
+		return fmt.Errorf("%q is not in %s format", value, name)
+	}
+	return nil
+}
+
+// Matches reports whether t falls within the rule's window
+func (r ScheduleRule) Matches(t time.Time) bool {
+	if len(r.Days) > 0 && !slices.ContainsFunc(r.Days, func(day string) bool {
+		return weekdays[strings.ToLower(day)] == t.Weekday()
+	}) {
+		return false
+	}
+
+	date := t.Format(dateLayout)
+	if (r.Start != "" && date < r.Start) || (r.End != "" && date > r.End) {
+		return false
+	}
+
+	if r.From == "" && r.Until == "" {
+		return true
+	}
+	clock := t.Format(clockLayout)
+	switch {
+	case r.Until == "":
+		return clock >= r.From
+	case r.From <= r.Until:
+		return clock >= r.From && clock < r.Until
+	default:
+		// Overnight window, e.g. 22:00-06:00
+		return clock >= r.From || clock < r.Until
+	}
+}
+
+// ScheduledURL returns the URL of the first schedule rule matching the
+// current time in loc, or false if none does and the link's own targets
+// apply. A nil loc means local time.
+func (l *Link) ScheduledURL(loc *time.Location) (string, bool) {
+	if len(l.Schedule) == 0 {
+		return "", false
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	if i, ok := l.ActiveRule(now().In(loc)); ok {
+		return l.Schedule[i].URL, true
+	}
+	return "", false
+}
+
+// ActiveRule returns the index of the first schedule rule matching t, in
+// t's location, or false if none does
+func (l *Link) ActiveRule(t time.Time) (int, bool) {
+	for i, rule := range l.Schedule {
+		if rule.Matches(t) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// String describes the rule's window, e.g. "mon,fri 09:00-10:00"
+func (r ScheduleRule) String() string {
+	var parts []string
+	if len(r.Days) > 0 {
+		parts = append(parts, strings.Join(r.Days, ","))
+	}
+	if r.From != "" || r.Until != "" {
+		parts = append(parts, r.From+"-"+r.Until)
+	}
+	switch {
+	case r.Start != "" && r.End != "":
+		parts = append(parts, r.Start+" to "+r.End)
+	case r.Start != "":
+		parts = append(parts, "from "+r.Start)
+	case r.End != "":
+		parts = append(parts, "until "+r.End)
+	}
+	if len(parts) == 0 {
+		return "always"
+	}
+	return strings.Join(parts, " ")
+}
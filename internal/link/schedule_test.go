@@ -0,0 +1,103 @@
+package link
+
+import (
+	"testing"
+	"time"
+)
+
+// at returns a time on 2026-10-12 plus days (a Monday) at hh:mm in loc
+func at(days, hour, minute int, loc *time.Location) time.Time {
+	return time.Date(2026, 10, 12+days, hour, minute, 0, 0, loc)
+}
+
+func TestScheduleRuleMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ScheduleRule
+		t    time.Time
+		want bool
+	}{
+		{"always", ScheduleRule{}, at(0, 12, 0, time.UTC), true},
+
+		{"window start is inclusive", ScheduleRule{From: "09:00", Until: "10:00"}, at(0, 9, 0, time.UTC), true},
+		{"window end is exclusive", ScheduleRule{From: "09:00", Until: "10:00"}, at(0, 10, 0, time.UTC), false},
+		{"before window", ScheduleRule{From: "09:00", Until: "10:00"}, at(0, 8, 59, time.UTC), false},
+		{"open-ended window", ScheduleRule{From: "17:00"}, at(0, 23, 59, time.UTC), true},
+
+		{"overnight, late evening", ScheduleRule{From: "22:00", Until: "06:00"}, at(0, 23, 0, time.UTC), true},
+		{"overnight, early morning", ScheduleRule{From: "22:00", Until: "06:00"}, at(0, 5, 59, time.UTC), true},
+		{"overnight, at end", ScheduleRule{From: "22:00", Until: "06:00"}, at(0, 6, 0, time.UTC), false},
+		{"overnight, midday", ScheduleRule{From: "22:00", Until: "06:00"}, at(0, 12, 0, time.UTC), false},
+
+		// Days are those of the request, so Monday's overnight window covers
+		// early Monday, not early Tuesday
+		{"overnight on a day, same morning", ScheduleRule{Days: []string{"mon"}, From: "22:00", Until: "06:00"}, at(0, 1, 0, time.UTC), true},
+		{"overnight on a day, next morning", ScheduleRule{Days: []string{"mon"}, From: "22:00", Until: "06:00"}, at(1, 1, 0, time.UTC), false},
+
+		{"matching day", ScheduleRule{Days: []string{"tue", "Thursday"}}, at(3, 12, 0, time.UTC), true},
+		{"other day", ScheduleRule{Days: []string{"tue", "thu"}}, at(2, 12, 0, time.UTC), false},
+
+		{"first date is inclusive", ScheduleRule{Start: "2026-10-12", End: "2026-10-14"}, at(0, 0, 0, time.UTC), true},
+		{"last date is inclusive", ScheduleRule{Start: "2026-10-12", End: "2026-10-14"}, at(2, 23, 59, time.UTC), true},
+		{"after date range", ScheduleRule{Start: "2026-10-12", End: "2026-10-14"}, at(3, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.t); got != tt.want {
+			t.Errorf("%s: %v.Matches(%s) = %v, want %v", tt.name, tt.rule, tt.t.Format(time.RFC1123), got, tt.want)
+		}
+	}
+}
+
+func TestActiveRuleOverlapping(t *testing.T) {
+	l := &Link{Schedule: []ScheduleRule{
+		{URL: "https://standup.example.com", Days: []string{"mon"}, From: "09:00", Until: "10:00"},
+		{URL: "https://office-hours.example.com", From: "08:00", Until: "12:00"},
+		{URL: "https://night.example.com", From: "22:00", Until: "06:00"},
+	}}
+
+	tests := []struct {
+		t      time.Time
+		want   int
+		wantOK bool
+	}{
+		{at(0, 9, 30, time.UTC), 0, true},  // Both daytime rules match; the first wins
+		{at(1, 9, 30, time.UTC), 1, true},  // Not Monday, so only the second
+		{at(0, 11, 0, time.UTC), 1, true},  // After the standup window
+		{at(0, 23, 0, time.UTC), 2, true},  // Overnight
+		{at(0, 14, 0, time.UTC), 0, false}, // No rule, so the link's own URL applies
+	}
+	for _, tt := range tests {
+		got, ok := l.ActiveRule(tt.t)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ActiveRule(%s) = %d, %v; want %d, %v", tt.t.Format(time.RFC1123), got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestScheduledURLTimezone(t *testing.T) {
+	l := &Link{URL: "https://example.com", Schedule: []ScheduleRule{
+		{URL: "https://standup.example.com", Days: []string{"tue"}, From: "09:00", Until: "10:00"},
+	}}
+
+	// Monday 23:30 UTC is Tuesday 09:30 in UTC+10
+	frozen := at(0, 23, 30, time.UTC)
+	now = func() time.Time { return frozen }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		loc    *time.Location
+		want   string
+		wantOK bool
+	}{
+		{time.UTC, "", false},
+		{time.FixedZone("UTC+10", 10*60*60), "https://standup.example.com", true},
+		{time.FixedZone("UTC-5", -5*60*60), "", false},
+	}
+	for _, tt := range tests {
+		got, ok := l.ScheduledURL(tt.loc)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ScheduledURL(%s) = %q, %v; want %q, %v", tt.loc, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupLayout names rotated files, e.g. golink.log.20260102-150405.000
+const backupLayout = "20060102-150405.000"
+
+// retryInterval is how long to wait after a failed rotation before trying
+// again, rather than retrying on every write
+const retryInterval = time.Minute
+
+// File operations, replaced in tests
+var (
+	openFile = os.OpenFile
+	rename   = os.Rename
+)
+
+// Writer is a log file that rotates itself once it grows past a size or
+// age. Rotated files get a timestamp suffix and only the newest are kept.
+// It is safe for concurrent use.
+type Writer struct {
+	path    string
+	maxSize int64         // Rotate before the file would grow past this many bytes (0 disables)
+	maxAge  time.Duration // Rotate once the file has been written to for this long (0 disables)
+	backups int           // Rotated files to keep (0 keeps all)
+
+	mutex   sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	retryAt time.Time // Don't rotate before this, after a failure
+}
+
+// Open opens path for appending, creating it if needed
+func Open(path string, maxSize int64, maxAge time.Duration, backups int) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSize, maxAge: maxAge, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the log file, picking up the size of an existing one. On
+// failure the current file, if any, is left in place.
+func (w *Writer) open() error {
+	f, err := openFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p would take it past
+// the size limit or it is past the age limit. If rotation fails, writing
+// carries on in the current file and rotation is retried a minute later.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.due(len(p)) {
+		if err := w.rotate(); err != nil {
+			w.retryAt = time.Now().Add(retryInterval)
+			fmt.Fprintf(os.Stderr, "Error rotating log file: %v\n", err)
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file should be rotated before writing n bytes.
+// An empty file is never rotated, so a single oversized write still lands.
+func (w *Writer) due(n int) bool {
+	if w.size == 0 || time.Now().Before(w.retryAt) {
+		return false
+	}
+	if w.maxSize > 0 && w.size+int64(n) > w.maxSize {
+		return true
+	}
+	return w.maxAge > 0 && time.Since(w.opened) >= w.maxAge
+}
+
+// rotate renames the current file with a timestamp suffix, starts a new one
+// and removes backups beyond the limit. The current file stays open until
+// the new one is, so a failure leaves logging going to the current file
+// (under its backup name, if only the open failed).
+func (w *Writer) rotate() error {
+	// After a failed open the file is already under its backup name
+	err := rename(w.path, w.path+"."+time.Now().Format(backupLayout))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	old := w.file
+	if err := w.open(); err != nil {
+		return err
+	}
+	old.Close()
+	return w.prune()
+}
+
+// prune removes the oldest rotated files beyond the backup limit
+func (w *Writer) prune() error {
+	if w.backups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return err
+	}
+
+	// Only touch our own backups, whose timestamp suffixes sort in time order
+	matches = slices.DeleteFunc(matches, func(m string) bool {
+		_, err := time.Parse(backupLayout, strings.TrimPrefix(m, w.path+"."))
+		return err != nil
+	})
+	sort.Strings(matches)
+	for len(matches) > w.backups {
+		if err := os.Remove(matches[0]); err != nil {
+			return err
+		}
+		matches = matches[1:]
+	}
+	return nil
+}
+
+// Reopen closes and reopens the file at its path, for when an external tool
+// such as logrotate has moved it away
+func (w *Writer) Reopen() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// Keep writing to the old file if the new one can't be opened
+	old := w.file
+	if err := w.open(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+// Close closes the file
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.file.Close()
+}
@@ -0,0 +1,119 @@
+package logfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readFile returns the contents of path, failing the test if it can't
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// backups returns the rotated files next to path
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golink.log")
+	w, err := Open(path, 10, 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+
+	if got := readFile(t, path); got != "second\n" {
+		t.Errorf("log file = %q, want %q", got, "second\n")
+	}
+	b := backups(t, path)
+	if len(b) != 1 || readFile(t, b[0]) != "first\n" {
+		t.Errorf("backups = %v, want one holding the first line", b)
+	}
+}
+
+func TestRotateOpenFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golink.log")
+	w, err := Open(path, 10, 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	opens := 0
+	openFile = func(string, int, os.FileMode) (*os.File, error) {
+		opens++
+		return nil, errors.New("disk full")
+	}
+	defer func() { openFile = os.OpenFile }()
+
+	// Rotation fails to open the new file, so lines keep going to the old
+	// one, now under its backup name
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v; want it written", line, n, err)
+		}
+	}
+	if opens != 1 {
+		t.Errorf("tried to open a new file %d times, want once before backing off", opens)
+	}
+	b := backups(t, path)
+	if len(b) != 1 || readFile(t, b[0]) != "one\ntwo\nthree\nfour\n" {
+		t.Fatalf("backups = %v, want one holding every line", b)
+	}
+
+	// Once the retry interval has passed, rotation starts a new file
+	openFile = os.OpenFile
+	w.retryAt = time.Time{}
+	w.Write([]byte("five\n"))
+	if got := readFile(t, path); got != "five\n" {
+		t.Errorf("log file = %q, want %q", got, "five\n")
+	}
+}
+
+func TestRotateRenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golink.log")
+	w, err := Open(path, 10, 0, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	renames := 0
+	rename = func(string, string) error {
+		renames++
+		return errors.New("permission denied")
+	}
+	defer func() { rename = os.Rename }()
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v; want it written", line, n, err)
+		}
+	}
+	if renames != 1 {
+		t.Errorf("tried to rename %d times, want once before backing off", renames)
+	}
+	if got := readFile(t, path); got != "one\ntwo\nthree\nfour\n" {
+		t.Errorf("log file = %q, want every line", got)
+	}
+	if b := backups(t, path); len(b) != 0 {
+		t.Errorf("backups = %v, want none", b)
+	}
+}
@@ -0,0 +1,162 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrBrowserNotFound is returned when a known browser isn't installed
+var ErrBrowserNotFound = errors.New("browser not found")
+
+// Browser describes how to launch a browser directly, so URLs can be opened
+// in a particular profile or a new window rather than with the system opener
+type Browser struct {
+	// Executables to look for on each system ("linux", "darwin", "windows"
+	// or "wsl"), in order. Names without a directory are looked up on PATH
+	// and ${VAR} is expanded. On macOS these are app bundles, which are
+	// launched with open. Under WSL they're Windows executables on /mnt/c.
+	Paths map[string][]string
+
+	ProfileArgs   func(profile string) []string // Arguments that select a profile
+	NewWindowArgs []string                      // Arguments that open the URL in a new window
+}
+
+// chromiumProfile selects a Chromium-based browser's profile by its
+// directory name, e.g. "Default" or "Profile 1"
+func chromiumProfile(profile string) []string {
+	return []string{"--profile-directory=" + profile}
+}
+
+// Browsers maps the names accepted by open --browser to how to launch them.
+// Add an entry to support another browser.
+var Browsers = map[string]Browser{
+	"chrome": {
+		Paths: map[string][]string{
+			"linux":   {"google-chrome", "google-chrome-stable"},
+			"darwin":  {"/Applications/Google Chrome.app"},
+			"windows": {`${ProgramFiles}\Google\Chrome\Application\chrome.exe`, `${ProgramFiles(x86)}\Google\Chrome\Application\chrome.exe`, `${LocalAppData}\Google\Chrome\Application\chrome.exe`},
+			"wsl":     {"/mnt/c/Program Files/Google/Chrome/Application/chrome.exe", "/mnt/c/Program Files (x86)/Google/Chrome/Application/chrome.exe"},
+		},
+		ProfileArgs:   chromiumProfile,
+		NewWindowArgs: []string{"--new-window"},
+	},
+	"chromium": {
+		Paths: map[string][]string{
+			"linux":  {"chromium", "chromium-browser"},
+			"darwin": {"/Applications/Chromium.app"},
+		},
+		ProfileArgs:   chromiumProfile,
+		NewWindowArgs: []string{"--new-window"},
+	},
+	"edge": {
+		Paths: map[string][]string{
+			"linux":   {"microsoft-edge", "microsoft-edge-stable"},
+			"darwin":  {"/Applications/Microsoft Edge.app"},
+			"windows": {`${ProgramFiles(x86)}\Microsoft\Edge\Application\msedge.exe`, `${ProgramFiles}\Microsoft\Edge\Application\msedge.exe`},
+			"wsl":     {"/mnt/c/Program Files (x86)/Microsoft/Edge/Application/msedge.exe", "/mnt/c/Program Files/Microsoft/Edge/Application/msedge.exe"},
+		},
+		ProfileArgs:   chromiumProfile,
+		NewWindowArgs: []string{"--new-window"},
+	},
+	"brave": {
+		Paths: map[string][]string{
+			"linux":   {"brave-browser", "brave"},
+			"darwin":  {"/Applications/Brave Browser.app"},
+			"windows": {`${ProgramFiles}\BraveSoftware\Brave-Browser\Application\brave.exe`, `${LocalAppData}\BraveSoftware\Brave-Browser\Application\brave.exe`},
+			"wsl":     {"/mnt/c/Program Files/BraveSoftware/Brave-Browser/Application/brave.exe"},
+		},
+		ProfileArgs:   chromiumProfile,
+		NewWindowArgs: []string{"--new-window"},
+	},
+	"firefox": {
+		Paths: map[string][]string{
+			"linux":   {"firefox"},
+			"darwin":  {"/Applications/Firefox.app"},
+			"windows": {`${ProgramFiles}\Mozilla Firefox\firefox.exe`, `${ProgramFiles(x86)}\Mozilla Firefox\firefox.exe`},
+			"wsl":     {"/mnt/c/Program Files/Mozilla Firefox/firefox.exe", "/mnt/c/Program Files (x86)/Mozilla Firefox/firefox.exe"},
+		},
+		// Profiles are selected by the name shown in about:profiles
+		ProfileArgs:   func(profile string) []string { return []string{"-P", profile} },
+		NewWindowArgs: []string{"-new-window"},
+	},
+}
+
+// BrowserNames returns the known browser names in order
+func BrowserNames() []string {
+	names := make([]string, 0, len(Browsers))
+	for name := range Browsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateBrowser checks that name is a known browser, or empty
+func ValidateBrowser(name string) error {
+	if _, ok := Browsers[name]; ok || name == "" {
+		return nil
+	}
+	return fmt.Errorf("unknown browser %q (use one of %v)", name, BrowserNames())
+}
+
+// StartRunner is a Runner that starts a command without waiting for it to
+// exit, so browsers started this way keep running after golink exits. It
+// returns no output.
+func StartRunner(stdin string, name string, args ...string) (string, error) {
+	c := exec.Command(name, args...)
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
+	}
+	if err := c.Start(); err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return "", c.Process.Release()
+}
+
+// lookPath returns the path of an installed executable, or an error. Tests
+// replace it to pretend browsers are installed.
+var lookPath = findExecutable
+
+// findExecutable looks a bare name up on PATH and checks that any other
+// path exists
+func findExecutable(path string) (string, error) {
+	if filepath.Base(path) == path {
+		return exec.LookPath(path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// browserCommand returns the executable and arguments that open url in the
+// named browser on system (a key of Browser.Paths), optionally with a
+// profile and in a new window. It returns ErrBrowserNotFound when the
+// browser isn't installed.
+func browserCommand(system, name, profile string, newWindow bool, url string) (string, []string, error) {
+	browser, ok := Browsers[name]
+	if !ok {
+		return "", nil, ValidateBrowser(name)
+	}
+
+	var args []string
+	if profile != "" {
+		args = append(args, browser.ProfileArgs(profile)...)
+	}
+	if newWindow {
+		args = append(args, browser.NewWindowArgs...)
+	}
+	args = append(args, url)
+
+	for _, candidate := range browser.Paths[system] {
+		if path, err := lookPath(os.ExpandEnv(candidate)); err == nil {
+			return path, args, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrBrowserNotFound, name)
+}
@@ -0,0 +1,35 @@
+package platform
+
+// Darwin implements Platform for macOS
+type Darwin struct {
+	Run Runner
+}
+
+// OpenURL opens a URL with the open command
+func (p *Darwin) OpenURL(url string) error {
+	_, err := p.Run("", "open", url)
+	return err
+}
+
+// OpenURLIn opens the browser's app bundle with the URL. open's -n starts
+// the app even if it's running, so the arguments apply, and returns without
+// waiting for it.
+func (p *Darwin) OpenURLIn(browser, profile string, newWindow bool, url string) error {
+	app, args, err := browserCommand("darwin", browser, profile, newWindow, url)
+	if err != nil {
+		return err
+	}
+	_, err = p.Run("", "open", append([]string{"-na", app, "--args"}, args...)...)
+	return err
+}
+
+// CopyToClipboard copies text with pbcopy
+func (p *Darwin) CopyToClipboard(text string) error {
+	_, err := p.Run(text, "pbcopy")
+	return err
+}
+
+// ReadClipboard reads text with pbpaste
+func (p *Darwin) ReadClipboard() (string, error) {
+	return p.Run("", "pbpaste")
+}
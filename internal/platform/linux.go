@@ -0,0 +1,42 @@
+package platform
+
+// Linux implements Platform for Linux desktops
+type Linux struct {
+	Run     Runner
+	Start   Runner // Starts browsers without waiting for them to exit
+	Wayland bool   // Use wl-clipboard instead of xclip
+}
+
+// OpenURL opens a URL with xdg-open
+func (p *Linux) OpenURL(url string) error {
+	_, err := p.Run("", "xdg-open", url)
+	return err
+}
+
+// OpenURLIn starts the browser's executable with the URL
+func (p *Linux) OpenURLIn(browser, profile string, newWindow bool, url string) error {
+	path, args, err := browserCommand("linux", browser, profile, newWindow, url)
+	if err != nil {
+		return err
+	}
+	_, err = p.Start("", path, args...)
+	return err
+}
+
+// CopyToClipboard copies text with wl-copy under Wayland, or xclip under X11
+func (p *Linux) CopyToClipboard(text string) error {
+	if p.Wayland {
+		_, err := p.Run(text, "wl-copy")
+		return err
+	}
+	_, err := p.Run(text, "xclip", "-selection", "clipboard")
+	return err
+}
+
+// ReadClipboard reads text with wl-paste under Wayland, or xclip under X11
+func (p *Linux) ReadClipboard() (string, error) {
+	if p.Wayland {
+		return p.Run("", "wl-paste", "--no-newline")
+	}
+	return p.Run("", "xclip", "-selection", "clipboard", "-o")
+}
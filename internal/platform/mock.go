@@ -0,0 +1,34 @@
+package platform
+
+// Mock is a Platform for tests that records calls instead of running commands
+type Mock struct {
+	Opened    []string // URLs passed to OpenURL and OpenURLIn
+	Browsers  []string // Browser names passed to OpenURLIn
+	Copied    []string // Text passed to CopyToClipboard
+	Clipboard string   // Text returned from ReadClipboard
+	Err       error    // Error returned from every call, if set
+}
+
+// OpenURL records the URL
+func (m *Mock) OpenURL(url string) error {
+	m.Opened = append(m.Opened, url)
+	return m.Err
+}
+
+// OpenURLIn records the URL and browser
+func (m *Mock) OpenURLIn(browser, profile string, newWindow bool, url string) error {
+	m.Opened = append(m.Opened, url)
+	m.Browsers = append(m.Browsers, browser)
+	return m.Err
+}
+
+// CopyToClipboard records the text
+func (m *Mock) CopyToClipboard(text string) error {
+	m.Copied = append(m.Copied, text)
+	return m.Err
+}
+
+// ReadClipboard returns the Clipboard field
+func (m *Mock) ReadClipboard() (string, error) {
+	return m.Clipboard, m.Err
+}
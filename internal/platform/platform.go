@@ -0,0 +1,74 @@
+package platform
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Platform wraps the operating system tools used to open URLs and use the clipboard
+type Platform interface {
+	// OpenURL opens a URL with the system's default handler
+	OpenURL(url string) error
+	// OpenURLIn opens a URL in the named browser (see Browsers), optionally
+	// with a profile and in a new window. It returns ErrBrowserNotFound
+	// when the browser isn't installed.
+	OpenURLIn(browser, profile string, newWindow bool, url string) error
+	// CopyToClipboard places text on the system clipboard
+	CopyToClipboard(text string) error
+	// ReadClipboard returns the text on the system clipboard
+	ReadClipboard() (string, error)
+}
+
+// Runner runs an external command with the given stdin and returns its stdout.
+// Implementations take a Runner so tests can inject a fake one.
+type Runner func(stdin string, name string, args ...string) (string, error)
+
+// ExecRunner runs commands with os/exec
+func ExecRunner(stdin string, name string, args ...string) (string, error) {
+	c := exec.Command(name, args...)
+	if stdin != "" {
+		c.Stdin = strings.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// Detect returns the Platform for the current operating system
+func Detect() (Platform, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return &Darwin{Run: ExecRunner}, nil
+	case "windows":
+		return &Windows{Run: ExecRunner, Start: StartRunner}, nil
+	case "linux":
+		if isWSL() {
+			return &WSL{Run: ExecRunner, Start: StartRunner}, nil
+		}
+		return &Linux{Run: ExecRunner, Start: StartRunner, Wayland: os.Getenv("WAYLAND_DISPLAY") != ""}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// isWSL reports whether we're running under Windows Subsystem for Linux
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
@@ -0,0 +1,194 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+// call is a command a Platform ran, with its stdin
+type call struct {
+	stdin string
+	args  []string
+}
+
+// recorder returns a Runner that records commands instead of running them,
+// and answers each with output
+func recorder(calls *[]call, output string) Runner {
+	return func(stdin string, name string, args ...string) (string, error) {
+		*calls = append(*calls, call{stdin, append([]string{name}, args...)})
+		return output, nil
+	}
+}
+
+func TestCommandLines(t *testing.T) {
+	const url = "https://example.com/a?b=1&c=2"
+
+	tests := []struct {
+		name  string
+		new   func(Runner) Platform
+		open  []string
+		copy  []string
+		paste []string
+	}{
+		{
+			"linux x11",
+			func(r Runner) Platform { return &Linux{Run: r} },
+			[]string{"xdg-open", url},
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xclip", "-selection", "clipboard", "-o"},
+		},
+		{
+			"linux wayland",
+			func(r Runner) Platform { return &Linux{Run: r, Wayland: true} },
+			[]string{"xdg-open", url},
+			[]string{"wl-copy"},
+			[]string{"wl-paste", "--no-newline"},
+		},
+		{
+			"darwin",
+			func(r Runner) Platform { return &Darwin{Run: r} },
+			[]string{"open", url},
+			[]string{"pbcopy"},
+			[]string{"pbpaste"},
+		},
+		{
+			"wsl",
+			func(r Runner) Platform { return &WSL{Run: r} },
+			[]string{"rundll32.exe", "url.dll,FileProtocolHandler", url},
+			[]string{"clip.exe"},
+			[]string{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"},
+		},
+		{
+			"windows",
+			func(r Runner) Platform { return &Windows{Run: r} },
+			[]string{"rundll32", "url.dll,FileProtocolHandler", url},
+			[]string{"clip"},
+			[]string{"powershell", "-NoProfile", "-Command", "Get-Clipboard"},
+		},
+	}
+
+	for _, tt := range tests {
+		var calls []call
+		p := tt.new(recorder(&calls, "clipboard text"))
+
+		if err := p.OpenURL(url); err != nil {
+			t.Errorf("%s: OpenURL: %v", tt.name, err)
+		}
+		if err := p.CopyToClipboard("copied"); err != nil {
+			t.Errorf("%s: CopyToClipboard: %v", tt.name, err)
+		}
+		if text, err := p.ReadClipboard(); err != nil || text != "clipboard text" {
+			t.Errorf("%s: ReadClipboard = %q, %v; want the command's output", tt.name, text, err)
+		}
+
+		want := []call{{"", tt.open}, {"copied", tt.copy}, {"", tt.paste}}
+		if !slices.EqualFunc(calls, want, func(a, b call) bool { return a.stdin == b.stdin && slices.Equal(a.args, b.args) }) {
+			t.Errorf("%s: ran %q, want %q", tt.name, calls, want)
+		}
+	}
+}
+
+func TestOpenURLIn(t *testing.T) {
+	const url = "https://example.com"
+
+	// Pretend only these executables are installed
+	installed := []string{
+		"google-chrome-stable",
+		"/Applications/Firefox.app",
+		"/mnt/c/Program Files (x86)/Microsoft/Edge/Application/msedge.exe",
+		`C:\Program Files\Mozilla Firefox\firefox.exe`,
+	}
+	lookPath = func(path string) (string, error) {
+		if slices.Contains(installed, path) {
+			return path, nil
+		}
+		return "", os.ErrNotExist
+	}
+	defer func() { lookPath = findExecutable }()
+	t.Setenv("ProgramFiles", `C:\Program Files`)
+
+	tests := []struct {
+		name      string
+		new       func(run, start Runner) Platform
+		browser   string
+		profile   string
+		newWindow bool
+		run       []string // Command run and waited for
+		start     []string // Command started in the background
+	}{
+		{
+			"linux",
+			func(run, start Runner) Platform { return &Linux{Run: run, Start: start} },
+			"chrome", "Profile 1", true,
+			nil,
+			[]string{"google-chrome-stable", "--profile-directory=Profile 1", "--new-window", url},
+		},
+		{
+			"darwin",
+			func(run, start Runner) Platform { return &Darwin{Run: run} },
+			"firefox", "work", false,
+			[]string{"open", "-na", "/Applications/Firefox.app", "--args", "-P", "work", url},
+			nil,
+		},
+		{
+			// WSL starts the Windows browser, not a Linux one on PATH
+			"wsl",
+			func(run, start Runner) Platform { return &WSL{Run: run, Start: start} },
+			"edge", "", true,
+			nil,
+			[]string{"/mnt/c/Program Files (x86)/Microsoft/Edge/Application/msedge.exe", "--new-window", url},
+		},
+		{
+			"windows",
+			func(run, start Runner) Platform { return &Windows{Run: run, Start: start} },
+			"firefox", "", false,
+			nil,
+			[]string{`C:\Program Files\Mozilla Firefox\firefox.exe`, url},
+		},
+	}
+
+	for _, tt := range tests {
+		var runs, starts []call
+		p := tt.new(recorder(&runs, ""), recorder(&starts, ""))
+		if err := p.OpenURLIn(tt.browser, tt.profile, tt.newWindow, url); err != nil {
+			t.Errorf("%s: OpenURLIn: %v", tt.name, err)
+			continue
+		}
+		if got := commands(runs); !slices.EqualFunc(got, wantCommands(tt.run), slices.Equal) {
+			t.Errorf("%s: ran %q, want %q", tt.name, got, tt.run)
+		}
+		if got := commands(starts); !slices.EqualFunc(got, wantCommands(tt.start), slices.Equal) {
+			t.Errorf("%s: started %q, want %q", tt.name, got, tt.start)
+		}
+	}
+
+	// Browsers that aren't installed are reported, so the caller can fall
+	// back to the default opener without having run anything
+	var calls []call
+	p := &Linux{Run: recorder(&calls, ""), Start: recorder(&calls, "")}
+	if err := p.OpenURLIn("brave", "", false, url); !errors.Is(err, ErrBrowserNotFound) {
+		t.Errorf("OpenURLIn(brave) = %v, want ErrBrowserNotFound", err)
+	}
+	if len(calls) > 0 {
+		t.Errorf("ran %q for a missing browser", calls)
+	}
+}
+
+// commands returns the command lines of calls
+func commands(calls []call) [][]string {
+	var lines [][]string
+	for _, c := range calls {
+		lines = append(lines, c.args)
+	}
+	return lines
+}
+
+// wantCommands returns the expected command lines for at most one command
+func wantCommands(line []string) [][]string {
+	if line == nil {
+		return nil
+	}
+	return [][]string{line}
+}
@@ -0,0 +1,35 @@
+package platform
+
+// Windows implements Platform for Windows
+type Windows struct {
+	Run   Runner
+	Start Runner // Starts browsers without waiting for them to exit
+}
+
+// OpenURL opens a URL with the default browser. rundll32 is used rather than
+// "cmd /c start" because cmd treats characters like & in the URL as syntax.
+func (p *Windows) OpenURL(url string) error {
+	_, err := p.Run("", "rundll32", "url.dll,FileProtocolHandler", url)
+	return err
+}
+
+// OpenURLIn starts the browser's executable with the URL
+func (p *Windows) OpenURLIn(browser, profile string, newWindow bool, url string) error {
+	path, args, err := browserCommand("windows", browser, profile, newWindow, url)
+	if err != nil {
+		return err
+	}
+	_, err = p.Start("", path, args...)
+	return err
+}
+
+// CopyToClipboard copies text with clip
+func (p *Windows) CopyToClipboard(text string) error {
+	_, err := p.Run(text, "clip")
+	return err
+}
+
+// ReadClipboard reads text with PowerShell's Get-Clipboard
+func (p *Windows) ReadClipboard() (string, error) {
+	return p.Run("", "powershell", "-NoProfile", "-Command", "Get-Clipboard")
+}
@@ -0,0 +1,35 @@
+package platform
+
+// WSL implements Platform for Windows Subsystem for Linux, handing URLs and
+// clipboard text to the Windows host since there's usually no Linux desktop
+type WSL struct {
+	Run   Runner
+	Start Runner // Starts browsers without waiting for them to exit
+}
+
+// OpenURL opens a URL in the Windows default browser
+func (p *WSL) OpenURL(url string) error {
+	_, err := p.Run("", "rundll32.exe", "url.dll,FileProtocolHandler", url)
+	return err
+}
+
+// OpenURLIn starts the Windows browser's executable through its /mnt/c path
+func (p *WSL) OpenURLIn(browser, profile string, newWindow bool, url string) error {
+	path, args, err := browserCommand("wsl", browser, profile, newWindow, url)
+	if err != nil {
+		return err
+	}
+	_, err = p.Start("", path, args...)
+	return err
+}
+
+// CopyToClipboard copies text to the Windows clipboard with clip.exe
+func (p *WSL) CopyToClipboard(text string) error {
+	_, err := p.Run(text, "clip.exe")
+	return err
+}
+
+// ReadClipboard reads the Windows clipboard through powershell.exe
+func (p *WSL) ReadClipboard() (string, error) {
+	return p.Run("", "powershell.exe", "-NoProfile", "-Command", "Get-Clipboard")
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// beaconRefreshSeconds is how long the meta refresh fallback waits, giving
+// the beacon image a moment to load when JavaScript is disabled
+const beaconRefreshSeconds = 1
+
+// useBeacon reports whether a redirect for l should go through the beacon
+// page: the link's own mode wins over the server-wide default, and nothing
+// is sent without a beacon URL
+func (s *Server) useBeacon(l *link.Link) bool {
+	if s.opts.BeaconURL == "" {
+		return false
+	}
+	mode := l.Redirect
+	if mode == "" {
+		mode = s.opts.RedirectMode
+	}
+	return mode == link.RedirectBeacon
+}
+
+// beaconURL fills the {alias} placeholder in the configured beacon URL
+func (s *Server) beaconURL(alias string) string {
+	return strings.ReplaceAll(s.opts.BeaconURL, "{alias}", url.QueryEscape(alias))
+}
+
+// writeBeaconPage sends a tiny page that fires the analytics beacon and then
+// navigates to target. With JavaScript the beacon is sent with sendBeacon and
+// the page redirects at once; without it the beacon loads as an image and a
+// meta refresh follows shortly after. target is written exactly as given,
+// only escaped for the context it appears in.
+func (s *Server) writeBeaconPage(w http.ResponseWriter, alias, target string) {
+	beacon := s.beaconURL(alias)
+	targetJS, _ := json.Marshal(target)
+	beaconJS, _ := json.Marshal(beacon)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta http-equiv="refresh" content="%d; url=%s">
+    <title>Redirecting...</title>
+    <script>
+        if (navigator.sendBeacon) { navigator.sendBeacon(%s); }
+        location.replace(%s);
+    </script>
+</head>
+<body>
+    <noscript><img src="%s" width="1" height="1" alt=""></noscript>
+    <p>Redirecting to <a href="%s">%s</a></p>
+</body>
+</html>
+`, beaconRefreshSeconds, html.EscapeString(target), beaconJS, targetJS,
+		html.EscapeString(beacon), html.EscapeString(target), html.EscapeString(target))
+}
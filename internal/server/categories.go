@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+)
+
+// CategoryStyle is how a category heading is shown on the homepage
+type CategoryStyle struct {
+	Color string `mapstructure:"color" json:"color,omitempty"`
+	Icon  string `mapstructure:"icon" json:"icon,omitempty"`
+}
+
+// colorPattern matches the CSS colors a category may use: #rgb, #rrggbb or a
+// color keyword like "teal"
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[a-zA-Z]+)$`)
+
+// Validate checks that the style can be rendered safely
+func (c CategoryStyle) Validate() error {
+	if c.Color != "" && !colorPattern.MatchString(c.Color) {
+		return fmt.Errorf("invalid color %q (use #rgb, #rrggbb or a color name)", c.Color)
+	}
+	return nil
+}
+
+// categoryHeading renders a category name with its configured icon and color.
+// Categories without a style are shown as plain text.
+func (s *Server) categoryHeading(name string) string {
+	style := s.opts.Categories[name]
+
+	heading := html.EscapeString(name)
+	if style.Icon != "" {
+		heading = html.EscapeString(style.Icon) + " " + heading
+	}
+	if style.Color != "" && style.Validate() == nil {
+		heading = fmt.Sprintf(`<span style="color: %s; font-weight: bold">%s</span>`, style.Color, heading)
+	}
+	return heading
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseNetworks([]string{"10.0.0.0/8", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{opts: Options{TrustedProxies: proxies}}
+
+	tests := []struct {
+		name   string
+		peer   string
+		xff    []string
+		realIP string
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+
+		// Untrusted peers can't pick their address with forwarding headers
+		{"untrusted peer with X-Forwarded-For", "203.0.113.7:5000", []string{"198.51.100.1"}, "", "203.0.113.7"},
+		{"untrusted peer with X-Real-IP", "203.0.113.7:5000", nil, "198.51.100.1", "203.0.113.7"},
+		{"untrusted peer claiming a trusted proxy", "203.0.113.7:5000", []string{"198.51.100.1, 10.0.0.1"}, "", "203.0.113.7"},
+
+		{"trusted proxy", "127.0.0.1:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"trusted proxy with X-Real-IP", "127.0.0.1:5000", nil, "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy without headers", "127.0.0.1:5000", nil, "", "127.0.0.1"},
+
+		// Multi-hop chains: walk back from the right past trusted proxies
+		// only, so a spoofed address the client prepended is never used
+		{"two trusted hops", "127.0.0.1:5000", []string{"198.51.100.1, 10.1.2.3"}, "", "198.51.100.1"},
+		{"spoofed leftmost entry", "127.0.0.1:5000", []string{"192.0.2.66, 198.51.100.1, 10.1.2.3"}, "", "198.51.100.1"},
+		{"chain split over headers", "127.0.0.1:5000", []string{"192.0.2.66", "198.51.100.1", "10.1.2.3"}, "", "198.51.100.1"},
+		{"only trusted hops", "127.0.0.1:5000", []string{"10.0.0.5, 10.1.2.3"}, "", "10.0.0.5"},
+		{"garbage hop stops the walk", "127.0.0.1:5000", []string{"198.51.100.1, not-an-ip"}, "", "127.0.0.1"},
+		{"IPv6 client", "127.0.0.1:5000", []string{"2001:db8::1"}, "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.peer
+		for _, v := range tt.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-IP", tt.realIP)
+		}
+		if got := s.clientIP(r); got != tt.want {
+			t.Errorf("%s: clientIP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	eventBufferSize    = 1024
+	eventBatchSize     = 50
+	eventFlushInterval = 5 * time.Second
+	eventMaxAttempts   = 3
+)
+
+// Event describes a single redirect served by the server
+type Event struct {
+	Alias     string    `json:"alias"`
+	Target    string    `json:"target"`
+	Timestamp time.Time `json:"timestamp"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Referer   string    `json:"referer,omitempty"`
+}
+
+// eventSink posts redirect events to a webhook in batches. Events are queued
+// on a buffered channel so a slow or unreachable webhook never blocks a redirect.
+// The channel is never closed, since handlers still running after a timed out
+// shutdown may send on it; closing stop tells the delivery goroutine to finish.
+type eventSink struct {
+	url      string
+	client   *http.Client
+	events   chan Event
+	stop     chan struct{} // Closed by close
+	stopOnce sync.Once
+	done     chan struct{} // Closed once queued events are delivered
+}
+
+// newEventSink creates an event sink and starts its delivery goroutine
+func newEventSink(url string) *eventSink {
+	e := &eventSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		events: make(chan Event, eventBufferSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// send queues an event, dropping it if the buffer is full or the sink is
+// closed
+func (e *eventSink) send(ev Event) {
+	select {
+	case <-e.stop:
+		return
+	default:
+	}
+
+	select {
+	case e.events <- ev:
+	default:
+		log.Printf("Warning: event buffer full, dropping event for %s", ev.Alias)
+	}
+}
+
+// close stops accepting events and waits for queued events to be
+// delivered. It may be called more than once.
+func (e *eventSink) close(ctx context.Context) error {
+	e.stopOnce.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run batches queued events and posts them until the sink is closed
+func (e *eventSink) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(eventFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, eventBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.post(batch); err != nil {
+			log.Printf("Error delivering %d events: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case ev := <-e.events:
+			batch = append(batch, ev)
+			if len(batch) >= eventBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			// Deliver what was queued before the sink was closed
+			for {
+				select {
+				case ev := <-e.events:
+					batch = append(batch, ev)
+					if len(batch) >= eventBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// post delivers a batch of events as a JSON array, retrying with backoff
+func (e *eventSink) post(batch []Event) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = e.postOnce(data)
+		if err == nil || attempt == eventMaxAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// postOnce makes a single delivery attempt
+func (e *eventSink) postOnce(data []byte) error {
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
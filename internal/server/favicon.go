@@ -0,0 +1,196 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+const (
+	faviconCacheSize     = 512
+	faviconTTL           = 24 * time.Hour
+	faviconFailureTTL    = time.Hour
+	faviconMaxBytes      = 100 << 10
+	faviconFetchInterval = 100 * time.Millisecond // At most 10 upstream fetches per second
+)
+
+// faviconPlaceholder is served when a site's icon can't be fetched
+const faviconPlaceholder = `<svg xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 16 16"><circle cx="8" cy="8" r="6" fill="#ccc"/></svg>`
+
+// favicon is a cached site icon. A nil data slice records a failed fetch.
+type favicon struct {
+	origin      string
+	data        []byte
+	contentType string
+	expires     time.Time
+}
+
+// faviconCache fetches site icons on behalf of the homepage, so browsing the
+// homepage doesn't reveal the user's links to third parties. Entries expire
+// after a TTL, the least recently used are evicted once the cache is full,
+// and upstream fetches are rate limited.
+type faviconCache struct {
+	client  *http.Client
+	limiter *time.Ticker
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used at the front
+}
+
+// newFaviconCache creates an empty favicon cache
+func newFaviconCache(client *http.Client) *faviconCache {
+	return &faviconCache{
+		client:  client,
+		limiter: time.NewTicker(faviconFetchInterval),
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached icon for an origin, fetching it if missing or expired
+func (c *faviconCache) get(ctx context.Context, origin string) *favicon {
+	c.mutex.Lock()
+	if elem, ok := c.entries[origin]; ok {
+		icon := elem.Value.(*favicon)
+		if time.Now().Before(icon.expires) {
+			c.order.MoveToFront(elem)
+			c.mutex.Unlock()
+			return icon
+		}
+	}
+	c.mutex.Unlock()
+
+	icon := c.fetch(ctx, origin)
+
+	// A fetch cut short because the client went away says nothing about the
+	// site, so don't remember it as a failure
+	if ctx.Err() != nil {
+		return icon
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[origin]; ok {
+		c.order.Remove(elem)
+	} else if c.order.Len() >= faviconCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*favicon).origin)
+	}
+	c.entries[origin] = c.order.PushFront(icon)
+
+	return icon
+}
+
+// fetch downloads /favicon.ico from an origin, waiting for the rate limiter
+func (c *faviconCache) fetch(ctx context.Context, origin string) *favicon {
+	failed := &favicon{origin: origin, expires: time.Now().Add(faviconFailureTTL)}
+
+	select {
+	case <-c.limiter.C:
+	case <-ctx.Done():
+		return failed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/favicon.ico", nil)
+	if err != nil {
+		return failed
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return failed
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return failed
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, faviconMaxBytes+1))
+	if err != nil || len(data) == 0 || len(data) > faviconMaxBytes {
+		return failed
+	}
+
+	// Only serve formats recognized by sniffing, which leaves out SVG: it
+	// can carry scripts that would run on the go link server's origin
+	contentType := http.DetectContentType(data)
+	if !strings.HasPrefix(contentType, "image/") {
+		return failed
+	}
+
+	return &favicon{
+		origin:      origin,
+		data:        data,
+		contentType: contentType,
+		expires:     time.Now().Add(faviconTTL),
+	}
+}
+
+// handleFaviconProxy serves the cached favicon for the site in the url
+// parameter. Only sites that stored links point at are fetched, so the proxy
+// can't be used to make the server request arbitrary hosts.
+func (s *Server) handleFaviconProxy(w http.ResponseWriter, r *http.Request) {
+	target, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		http.Error(w, "url must be an absolute http(s) URL", http.StatusBadRequest)
+		return
+	}
+	origin := target.Scheme + "://" + target.Host
+	if !s.linksToOrigin(origin) {
+		http.Error(w, "no link points at "+origin, http.StatusForbidden)
+		return
+	}
+
+	icon := s.favicons.get(r.Context(), origin)
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", int(faviconTTL.Seconds())))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if icon.data == nil {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		fmt.Fprint(w, faviconPlaceholder)
+		return
+	}
+
+	w.Header().Set("Content-Type", icon.contentType)
+	w.Write(icon.data)
+}
+
+// linksToOrigin reports whether any stored link's URL is on origin
+func (s *Server) linksToOrigin(origin string) bool {
+	found := false
+	s.storage.ForEach(func(l *link.Link) bool {
+		for _, raw := range l.URLs() {
+			if hasOrigin(raw, origin) {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// hasOrigin reports whether raw is a URL on origin, ignoring case
+func hasOrigin(raw, origin string) bool {
+	if len(raw) < len(origin) || !strings.EqualFold(raw[:len(origin)], origin) {
+		return false
+	}
+	rest := raw[len(origin):]
+	return rest == "" || strings.ContainsRune("/?#", rune(rest[0]))
+}
+
+// faviconImg returns the homepage <img> tag for a link's favicon
+func faviconImg(target string) string {
+	return fmt.Sprintf(`<img src="/favicon-proxy?url=%s" width="16" height="16" alt=""> `, url.QueryEscape(target))
+}
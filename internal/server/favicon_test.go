@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+func TestFaviconProxy(t *testing.T) {
+	var fetches atomic.Int32
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(document.cookie)</script></svg>`
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if strings.HasPrefix(r.Host, "localhost:") {
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Write([]byte(svg))
+			return
+		}
+		w.Write([]byte(png))
+	}))
+	defer upstream.Close()
+
+	// The same upstream under a second host name, serving an SVG icon
+	u, _ := url.Parse(upstream.URL)
+	svgOrigin := "http://localhost:" + u.Port()
+	s := newTestServer(t, Options{Favicons: true},
+		link.NewLink("docs", upstream.URL+"/docs", "", ""),
+		link.NewLink("drawing", svgOrigin+"/", "", ""),
+	)
+
+	tests := []struct {
+		name        string
+		url         string
+		status      int
+		contentType string
+		fetched     bool
+	}{
+		{"linked site", upstream.URL + "/other/page", http.StatusOK, "image/png", true},
+		{"unlinked site", "http://169.254.169.254/latest/meta-data", http.StatusForbidden, "", false},
+		{"same host, other port", "http://" + u.Hostname() + ":1", http.StatusForbidden, "", false},
+		{"SVG icon gets the placeholder", svgOrigin, http.StatusOK, "image/svg+xml", true},
+	}
+	for _, tt := range tests {
+		before := fetches.Load()
+		w := httptest.NewRecorder()
+		s.handleFaviconProxy(w, httptest.NewRequest(http.MethodGet, "/favicon-proxy?url="+url.QueryEscape(tt.url), nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: Content-Type %q, want %q", tt.name, w.Header().Get("Content-Type"), tt.contentType)
+		}
+		if strings.Contains(w.Body.String(), "<script>") {
+			t.Errorf("%s: served the upstream SVG", tt.name)
+		}
+		if fetched := fetches.Load() > before; fetched != tt.fetched {
+			t.Errorf("%s: fetched upstream %v, want %v", tt.name, fetched, tt.fetched)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// healthPath is the health check endpoint, which may not count as activity
+const healthPath = "/healthz"
+
+// idleTracker records when the server last handled a request and signals
+// once it has been idle for the configured timeout
+type idleTracker struct {
+	timeout     time.Duration
+	countHealth bool
+	last        atomic.Int64 // Unix nanoseconds of the last request
+	idle        chan struct{}
+}
+
+// newIdleTracker creates a tracker and starts its checker goroutine
+func newIdleTracker(timeout time.Duration, countHealth bool) *idleTracker {
+	t := &idleTracker{
+		timeout:     timeout,
+		countHealth: countHealth,
+		idle:        make(chan struct{}),
+	}
+	t.last.Store(time.Now().UnixNano())
+	go t.run()
+	return t
+}
+
+// middleware records the time of each request before handling it
+func (t *idleTracker) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t.countHealth || r.URL.Path != healthPath {
+			t.last.Store(time.Now().UnixNano())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// run closes the idle channel once no requests arrive for the timeout. It
+// checks ten times per timeout, between every 10ms and every second.
+func (t *idleTracker) run() {
+	interval := min(max(t.timeout/10, 10*time.Millisecond), time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if time.Since(time.Unix(0, t.last.Load())) >= t.timeout {
+			close(t.idle)
+			return
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdleTracker(t *testing.T) {
+	// Timeouts too short to divide into check intervals still work
+	for _, timeout := range []time.Duration{time.Nanosecond, 9 * time.Nanosecond, 50 * time.Millisecond} {
+		tracker := newIdleTracker(timeout, false)
+		select {
+		case <-tracker.idle:
+		case <-time.After(time.Second + timeout):
+			t.Errorf("idle timeout %s: not idle after %s", timeout, time.Second+timeout)
+		}
+	}
+}
+
+func TestIdleTrackerHealthChecks(t *testing.T) {
+	tracker := newIdleTracker(200*time.Millisecond, false)
+	handler := tracker.middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	// Health checks alone don't keep the server alive
+	deadline := time.After(2 * time.Second)
+	for {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, healthPath, nil))
+		select {
+		case <-tracker.idle:
+			return
+		case <-deadline:
+			t.Fatal("health checks kept the server from going idle")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
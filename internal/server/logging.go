@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// statusRecorder captures the response status for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	notFound bool
+	target   string // Target chosen for a link with several or a schedule, logged after the request
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// markNotFound flags a request as a missing alias so it is always logged,
+// even when it was answered with a redirect to the not-found URL
+func markNotFound(w http.ResponseWriter) {
+	if rec, ok := w.(*statusRecorder); ok {
+		rec.notFound = true
+	}
+}
+
+// markTarget records which of a link's targets or scheduled URLs a request
+// was redirected to, so it appears in the request log
+func markTarget(w http.ResponseWriter, target string) {
+	if rec, ok := w.(*statusRecorder); ok {
+		rec.target = target
+	}
+}
+
+// Request log levels, from most to least verbose
+const (
+	LogDebug = "debug" // Every request, ignoring skipped paths and sampling
+	LogInfo  = "info"  // Sampled successful requests, plus all errors and not-founds
+	LogWarn  = "warn"  // Only client and server errors and not-founds
+	LogError = "error" // Only server errors
+)
+
+// ValidateLogLevel checks that level is a known request log level, or empty
+// for the default
+func ValidateLogLevel(level string) error {
+	switch level {
+	case "", LogDebug, LogInfo, LogWarn, LogError:
+		return nil
+	}
+	return fmt.Errorf("unknown log level %q (use %s, %s, %s or %s)", level, LogDebug, LogInfo, LogWarn, LogError)
+}
+
+// logged reports whether a finished request should be written to the
+// request log at the server's log level
+func (s *Server) logged(r *http.Request, rec *statusRecorder) bool {
+	switch s.opts.LogLevel {
+	case LogDebug:
+		return true
+	case LogWarn:
+		return rec.status >= 400 || rec.notFound
+	case LogError:
+		return rec.status >= 500
+	}
+
+	if rec.status < 400 && !rec.notFound {
+		if slices.Contains(s.opts.LogSkipPaths, r.URL.Path) {
+			return false
+		}
+		if rate := math.Float64frombits(s.sample.Load()); rate < 1 && rand.Float64() >= rate {
+			return false
+		}
+	}
+	return true
+}
+
+// SetLogSampleRate changes the fraction of successful requests that are
+// logged. It is safe to call while the server is running.
+func (s *Server) SetLogSampleRate(rate float64) {
+	s.sample.Store(math.Float64bits(min(max(rate, 0), 1)))
+}
+
+// logMiddleware logs incoming requests. At the default info level,
+// successful requests to skipped paths are never logged and the rest are
+// sampled; errors and not-founds are always logged.
+func (s *Server) logMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		// Call the next handler
+		next.ServeHTTP(rec, r)
+
+		if !s.logged(r, rec) {
+			return
+		}
+
+		// Log the request, with the chosen target for multi-target and scheduled links
+		line := fmt.Sprintf(
+			"%s %s %s %d %s",
+			s.clientIP(r),
+			r.Method,
+			r.RequestURI,
+			rec.status,
+			time.Since(start),
+		)
+		if rec.target != "" {
+			line += " -> " + rec.target
+		}
+		log.Print(line)
+	})
+}
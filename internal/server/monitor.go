@@ -0,0 +1,158 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+)
+
+// Link reachability states reported by the monitor
+const (
+	StatusOK          = "ok"
+	StatusUnreachable = "unreachable"
+)
+
+// LinkStatus is the result of the most recent reachability check for a link
+type LinkStatus struct {
+	Alias     string    `json:"alias"`
+	URL       string    `json:"url"`
+	Status    string    `json:"status"`
+	Code      int       `json:"code,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// monitor periodically HEAD-checks every link's target in the background
+type monitor struct {
+	storage     *storage.JSONStorage
+	client      *http.Client
+	interval    time.Duration
+	concurrency int
+
+	mutex   sync.RWMutex
+	results map[string]LinkStatus
+	stop    chan struct{}
+}
+
+// newMonitor creates a monitor and starts its check loop
+func newMonitor(storage *storage.JSONStorage, client *http.Client, interval time.Duration, concurrency int) *monitor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	m := &monitor{
+		storage:     storage,
+		client:      client,
+		interval:    interval,
+		concurrency: concurrency,
+		results:     make(map[string]LinkStatus),
+		stop:        make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// run checks all links immediately and then once per interval until closed
+func (m *monitor) run() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.checkAll()
+
+		select {
+		case <-ticker.C:
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// close stops the check loop
+func (m *monitor) close() {
+	close(m.stop)
+}
+
+// checkAll checks every link with bounded concurrency and replaces the results
+func (m *monitor) checkAll() {
+	links := m.storage.List()
+	results := make(map[string]LinkStatus, len(links))
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.concurrency)
+
+	for _, l := range links {
+		// Deeplinks and file:// targets can't be fetched over HTTP, and
+		// pattern links only have a URL once a request fills it in
+		if !l.IsWeb() || l.Pattern != "" {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(l *link.Link) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			status := m.check(l)
+			mutex.Lock()
+			results[l.Alias] = status
+			mutex.Unlock()
+		}(l)
+	}
+	wg.Wait()
+
+	m.mutex.Lock()
+	m.results = results
+	m.mutex.Unlock()
+}
+
+// check makes a HEAD request to a link's target, falling back to GET for
+// servers that don't support HEAD
+func (m *monitor) check(l *link.Link) LinkStatus {
+	status := LinkStatus{Alias: l.Alias, URL: l.URL, CheckedAt: time.Now()}
+
+	// Check templated links with their placeholders left empty
+	target, _ := l.Expand(nil, false)
+
+	resp, err := m.client.Head(target)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = m.client.Get(target)
+	}
+	if err != nil {
+		status.Status = StatusUnreachable
+		status.Error = err.Error()
+		return status
+	}
+	resp.Body.Close()
+
+	status.Code = resp.StatusCode
+	// Pages behind a login still exist, so don't flag them
+	if resp.StatusCode < 400 || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		status.Status = StatusOK
+	} else {
+		status.Status = StatusUnreachable
+	}
+	return status
+}
+
+// statuses returns the latest results sorted by alias
+func (m *monitor) statuses() []LinkStatus {
+	m.mutex.RLock()
+	result := make([]LinkStatus, 0, len(m.results))
+	for _, status := range m.results {
+		result = append(result, status)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Alias < result[j].Alias
+	})
+	return result
+}
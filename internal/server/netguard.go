@@ -0,0 +1,63 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// fetchClient returns the HTTP client used when the server itself fetches a
+// link's target (reachability checks, favicons). With BlockPrivate set it
+// refuses to connect to private, loopback and link-local addresses unless
+// they're in AllowPrivate. The check runs on the resolved address at dial
+// time, so hostnames that resolve to internal IPs are caught as well.
+// Redirects are unaffected since the browser follows those itself.
+func (s *Server) fetchClient(timeout time.Duration) *http.Client {
+	if !s.opts.BlockPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: s.guardDial, Resolver: s.resolver}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would hide the real destination from the guard
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// guardDial rejects connections to private addresses that aren't allowlisted
+func (s *Server) guardDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("refusing to connect to unresolved address %s", host)
+	}
+	if !isPrivateIP(ip) {
+		return nil
+	}
+
+	for _, n := range s.opts.AllowPrivate {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to connect to private address %s", ip)
+}
+
+// isPrivateIP reports whether ip is in an RFC 1918/4193 private, loopback,
+// link-local or unspecified range
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() ||
+		ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified()
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubResolver returns a resolver that answers every A query with ip, as a
+// DNS server pointing hostnames at internal addresses would
+func stubResolver(ip net.IP) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveStubDNS(server, ip.To4())
+			return client, nil
+		},
+	}
+}
+
+// serveStubDNS answers DNS queries over a stream connection, which carries
+// each message after a two-byte length
+func serveStubDNS(conn net.Conn, ip net.IP) {
+	defer conn.Close()
+	for {
+		var size uint16
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		query := make([]byte, size)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		// The question follows the 12-byte header: a name ending in a zero
+		// length label, then its type and class
+		end := 12
+		for end < len(query) && query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+		end += 5
+		if end > len(query) {
+			return
+		}
+		qtype := binary.BigEndian.Uint16(query[end-4:])
+
+		answers := uint16(0)
+		if qtype == 1 { // A
+			answers = 1
+		}
+		resp := append([]byte{}, query[:2]...)              // ID
+		resp = append(resp, 0x81, 0x80)                     // Response, recursion available
+		resp = binary.BigEndian.AppendUint16(resp, 1)       // Questions
+		resp = binary.BigEndian.AppendUint16(resp, answers) // Answers
+		resp = append(resp, 0, 0, 0, 0)                     // Authority and additional records
+		resp = append(resp, query[12:end]...)               // The question
+		if answers > 0 {
+			resp = append(resp, 0xc0, 12)                  // Name, pointing at the question
+			resp = append(resp, 0, 1, 0, 1)                // Type A, class IN
+			resp = binary.BigEndian.AppendUint32(resp, 60) // TTL
+			resp = binary.BigEndian.AppendUint16(resp, 4)  // Address length
+			resp = append(resp, ip...)
+		}
+
+		out := binary.BigEndian.AppendUint16(nil, uint16(len(resp)))
+		if _, err := conn.Write(append(out, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+func TestFetchClientBlocksHostnamesResolvingToPrivateIPs(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer upstream.Close()
+	u, _ := url.Parse(upstream.URL)
+	target := "http://internal.example.test:" + u.Port() + "/"
+
+	allowLoopback, err := ParseNetworks([]string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		blocked bool
+	}{
+		{"blocked", Options{BlockPrivate: true}, true},
+		{"allowlisted range", Options{BlockPrivate: true, AllowPrivate: allowLoopback}, false},
+	}
+
+	for _, tt := range tests {
+		s := &Server{opts: tt.opts, resolver: stubResolver(net.ParseIP("127.0.0.1"))}
+		resp, err := s.fetchClient(5 * time.Second).Get(target)
+		if tt.blocked {
+			if err == nil {
+				resp.Body.Close()
+				t.Errorf("%s: fetch succeeded, want it refused", tt.name)
+			} else if !strings.Contains(err.Error(), "refusing to connect to private address 127.0.0.1") {
+				t.Errorf("%s: fetch failed with %v, want a refusal", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: fetch failed: %v", tt.name, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestGuardDial(t *testing.T) {
+	allow, err := ParseNetworks([]string{"10.1.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{opts: Options{BlockPrivate: true, AllowPrivate: allow}}
+
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1::]:443", true},
+		{"10.1.2.3:80", true}, // Allowlisted
+		{"10.2.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"172.16.0.1:80", false},
+		{"127.0.0.1:80", false},
+		{"169.254.169.254:80", false}, // Cloud metadata
+		{"0.0.0.0:80", false},
+		{"[::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[fe80::1]:80", false},
+		{"[::ffff:192.168.1.1]:80", false},  // IPv4-mapped
+		{"internal.example.test:80", false}, // Not resolved
+	}
+	for _, tt := range tests {
+		err := s.guardDial("tcp", tt.address, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("guardDial(%s) = %v, want allowed %v", tt.address, err, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxNotFoundEntries bounds how many distinct missing aliases are tracked
+const maxNotFoundEntries = 1000
+
+// NotFoundEntry records how often a missing alias was requested
+type NotFoundEntry struct {
+	Alias    string    `json:"alias"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// notFoundTracker counts requests for aliases that don't exist. Once full,
+// the least recently requested alias is evicted so a scanner hitting random
+// paths can't grow it without bound.
+type notFoundTracker struct {
+	mutex   sync.Mutex
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List // Most recently seen at the front
+}
+
+// newNotFoundTracker creates a tracker holding at most maxSize aliases
+func newNotFoundTracker(maxSize int) *notFoundTracker {
+	return &notFoundTracker{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// record counts a request for a missing alias
+func (t *notFoundTracker) record(alias string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if elem, ok := t.entries[alias]; ok {
+		entry := elem.Value.(*NotFoundEntry)
+		entry.Count++
+		entry.LastSeen = time.Now()
+		t.order.MoveToFront(elem)
+		return
+	}
+
+	if t.order.Len() >= t.maxSize {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.entries, oldest.Value.(*NotFoundEntry).Alias)
+	}
+
+	entry := &NotFoundEntry{Alias: alias, Count: 1, LastSeen: time.Now()}
+	t.entries[alias] = t.order.PushFront(entry)
+}
+
+// top returns up to n entries ordered by count, most requested first
+func (t *notFoundTracker) top(n int) []NotFoundEntry {
+	t.mutex.Lock()
+	result := make([]NotFoundEntry, 0, t.order.Len())
+	for elem := t.order.Front(); elem != nil; elem = elem.Next() {
+		result = append(result, *elem.Value.(*NotFoundEntry))
+	}
+	t.mutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Alias < result[j].Alias
+	})
+
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// recent returns up to n entries ordered by when they were last requested,
+// most recent first
+func (t *notFoundTracker) recent(n int) []NotFoundEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	result := make([]NotFoundEntry, 0, min(n, t.order.Len()))
+	for elem := t.order.Front(); elem != nil && (n <= 0 || len(result) < n); elem = elem.Next() {
+		result = append(result, *elem.Value.(*NotFoundEntry))
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// handleProxyAutoConfig serves a PAC file that sends requests for the go
+// hostname through this server, so go/alias works without binding port 80
+// or editing the hosts file. The server handles the proxied request like any
+// other, since it only looks at the path.
+func (s *Server) handleProxyAutoConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+
+	fmt.Fprintf(w, `function FindProxyForURL(url, host) {
+  if (host == "go") {
+    return "PROXY 127.0.0.1:%d";
+  }
+  return "DIRECT";
+}
+`, s.opts.Port)
+}
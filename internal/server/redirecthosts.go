@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HostAllowlist limits the hosts the server redirects browsers to. An entry
+// is either a host name, which matches only that host, or "*." followed by a
+// domain, which matches any subdomain of it but not the domain itself. An
+// empty allowlist allows every host.
+type HostAllowlist []string
+
+// ParseHostAllowlist checks and normalizes allowed_redirect_hosts entries
+func ParseHostAllowlist(entries []string) (HostAllowlist, error) {
+	var list HostAllowlist
+	for _, entry := range entries {
+		entry = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+		if entry == "" {
+			continue
+		}
+
+		domain := strings.TrimPrefix(entry, "*.")
+		if domain == "" || strings.ContainsAny(domain, "/:*@ ") {
+			return nil, fmt.Errorf("invalid host %q (use a host name like example.com, or *.example.com for its subdomains)", entry)
+		}
+		list = append(list, entry)
+	}
+	return list, nil
+}
+
+// Allows reports whether the server may redirect to target. Only http and
+// https URLs are checked against the hosts; other schemes are governed by
+// the allowed schemes instead. URLs without a scheme are refused, since
+// browsers resolve "//host" against the go link server's own scheme.
+func (a HostAllowlist) Allows(target string) bool {
+	if len(a) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+	case "":
+		return false
+	default:
+		return true
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return false
+	}
+	for _, entry := range a {
+		if domain, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == entry {
+			return true
+		}
+	}
+	return false
+}
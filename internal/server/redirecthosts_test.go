@@ -0,0 +1,63 @@
+package server
+
+import "testing"
+
+func TestHostAllowlistAllows(t *testing.T) {
+	allowed, err := ParseHostAllowlist([]string{"example.com", "*.corp.example", " Docs.Example.org. "})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		// Exact hosts
+		{"https://example.com/a", true},
+		{"http://EXAMPLE.com:8080/a", true},
+		{"https://example.com./a", true},
+		{"https://docs.example.org/", true},
+		{"https://www.example.com/", false},
+
+		// Subdomains only match a *. entry, and only real subdomains
+		{"https://wiki.corp.example/", true},
+		{"https://a.b.corp.example/", true},
+		{"https://corp.example/", false},
+		{"https://evilcorp.example/", false},
+		{"https://corp.example.evil.com/", false},
+
+		// Tricks that put an allowed name outside the host
+		{"https://example.com@evil.com/", false},
+		{"https://evil.com/example.com", false},
+		{"https://evil.com?example.com", false},
+
+		// Scheme checks: only http(s) is checked by host, and URLs without a
+		// scheme are refused since browsers resolve them against the server
+		{"//evil.com/", false},
+		{"/local/path", false},
+		{"slack://open?team=T1", true},
+		{"mailto:someone@evil.com", true},
+		{"https:///nohost", false},
+	}
+
+	for _, tt := range tests {
+		if got := allowed.Allows(tt.target); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestHostAllowlistEmpty(t *testing.T) {
+	var allowed HostAllowlist
+	if !allowed.Allows("https://anything.example/") || !allowed.Allows("//evil.com/") {
+		t.Error("an empty allowlist should allow every target")
+	}
+}
+
+func TestParseHostAllowlistInvalid(t *testing.T) {
+	for _, entry := range []string{"*.", "example.com/path", "user@example.com", "*.*.example.com", "example.com:80"} {
+		if _, err := ParseHostAllowlist([]string{entry}); err == nil {
+			t.Errorf("ParseHostAllowlist(%q) succeeded, want an error", entry)
+		}
+	}
+}
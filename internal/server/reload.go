@@ -0,0 +1,56 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// handleReload forces the storage to re-read its file, for when links.json
+// was edited out-of-band and the file watcher hasn't (or can't) pick it up
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorizedAdmin(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	count, err := s.storage.Reload()
+	if err != nil {
+		http.Error(w, "reload failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"links": count})
+}
+
+// authorizedAdmin checks access to admin endpoints. With an admin token
+// configured the request must carry it as a bearer token; without one, only
+// requests made directly from this machine are allowed. Behind trusted
+// proxies a local peer may be relaying anyone's request, so the token is
+// required.
+func (s *Server) authorizedAdmin(r *http.Request) bool {
+	if s.opts.AdminToken != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.AdminToken)) == 1
+	}
+	if len(s.opts.TrustedProxies) > 0 {
+		return false
+	}
+
+	// Use the direct peer, not forwarding headers, which a client could set
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+)
+
+// ErrTargetNotAllowed is returned (wrapped) when a link resolves to a URL
+// the server won't redirect to, because of its allowed schemes or hosts
+var ErrTargetNotAllowed = errors.New("target not allowed")
+
+// ResolveContext is everything a redirect depends on besides the requested
+// path: the links, the request's parameters and the server's settings
+type ResolveContext struct {
+	Storage      *storage.JSONStorage
+	Params       map[string]string // Values for {name} placeholders, e.g. from the query string
+	StrictParams bool              // Fail if placeholders are left unfilled
+	AliasSpace   string            // Character spaces in the path are turned into
+
+	Time time.Time               // When schedules are evaluated (zero means link.Now), in its location
+	Pick func(*link.Link) string // Chooses among a link's targets (nil uses the first)
+
+	RedirectSchemes      []string      // Non-HTTP schemes that may be redirected to
+	AllowedRedirectHosts HostAllowlist // Hosts web targets may redirect to (empty allows all)
+}
+
+// Resolution is where Resolve sends a request
+type Resolution struct {
+	URL  string   // The final URL
+	Rule string   // The rules that led there, e.g. "link a, alias b"
+	Hops []string // Links followed, starting with the one named by the path or the pattern link it matched
+}
+
+// resolution is where a request ends up
+type resolution struct {
+	requested *link.Link // The link named by the path, or the pattern link it matched
+	final     *link.Link // The link owning the URL, after alias: references
+	target    string
+	rule      string
+	hops      []string
+}
+
+// Resolve works out where the server sends a request for path, without
+// serving it: it finds the link (or pattern link) for the path, follows
+// alias: references, applies schedules, picks a target, fills placeholders
+// and checks the target is one the server redirects to. Unknown aliases
+// return storage.ErrNotFound. If following alias: references fails, Hops
+// holds the links followed until then.
+//
+// The server's redirects, its RPC service and the resolve commands all go
+// through Resolve, so they can't disagree about where a link leads.
+func Resolve(path string, ctx ResolveContext) (Resolution, error) {
+	res, err := resolve(path, ctx, true)
+	return Resolution{URL: res.target, Rule: res.rule, Hops: res.hops}, err
+}
+
+// resolve is Resolve, keeping the links involved for handleRedirect. The
+// rules are only described if describe is set, to keep redirects fast.
+func resolve(path string, ctx ResolveContext, describe bool) (resolution, error) {
+	alias := link.NormalizeAlias(strings.TrimPrefix(path, "/"), ctx.AliasSpace)
+
+	// Stored links are never modified in place, so the uncopied links are
+	// safe to read here
+	var rules, hops []string
+	l, ok := ctx.Storage.LookupRedirect(alias)
+	if ok && describe {
+		rules = append(rules, "link "+l.Alias)
+	}
+
+	// Aliases without a link of their own may match a pattern link, whose
+	// URL comes back with the captured groups filled in
+	var patternTarget string
+	if !ok {
+		l, patternTarget, ok = ctx.Storage.MatchPattern(alias)
+		if ok && describe {
+			rules = append(rules, fmt.Sprintf("pattern %s (%s)", l.Alias, l.Pattern))
+			hops = append(hops, l.Alias)
+		}
+	}
+	if !ok {
+		return resolution{}, fmt.Errorf("%w: %s", storage.ErrNotFound, alias)
+	}
+
+	// Follow alias: references to the link that owns the URL, picking one
+	// of each link's targets on the way
+	requested, final, raw := l, l, patternTarget
+	if l.Pattern == "" {
+		var err error
+		final, raw, err = link.FollowAliases(l, func(l *link.Link) string {
+			target, rule := ctx.pick(l, describe)
+			if !describe {
+				return target
+			}
+			hops = append(hops, l.Alias)
+			if rule != "" {
+				rules = append(rules, rule)
+			}
+			if ref, ok := link.AliasRef(target); ok {
+				rules = append(rules, "alias "+ref)
+			}
+			return target
+		}, ctx.Storage.LookupRedirect)
+		if err != nil {
+			return resolution{hops: hops}, err
+		}
+	}
+
+	target := raw
+	if len(final.Params()) > 0 {
+		var err error
+		if target, err = final.ExpandURL(raw, ctx.Params, ctx.StrictParams); err != nil {
+			return resolution{}, err
+		}
+	}
+
+	// Only send browsers to other schemes (deeplinks, file://) when allowed
+	if scheme := link.URLScheme(raw); scheme != "http" && scheme != "https" && !ctx.redirectsScheme(scheme) {
+		return resolution{}, fmt.Errorf("%s points at a %s: URL, which this server doesn't redirect to (use golink open %s): %w", final.Alias, scheme, final.Alias, ErrTargetNotAllowed)
+	}
+
+	// Refuse targets outside the redirect allowlist, so a link or query
+	// parameter can't turn the server into an open redirect
+	if !ctx.AllowedRedirectHosts.Allows(target) {
+		return resolution{}, fmt.Errorf("%s points at a host this server doesn't redirect to: %w", final.Alias, ErrTargetNotAllowed)
+	}
+
+	return resolution{
+		requested: requested,
+		final:     final,
+		target:    target,
+		rule:      strings.Join(rules, ", "),
+		hops:      hops,
+	}, nil
+}
+
+// pick chooses the URL to use for l: the URL of a schedule rule matching
+// the context's time, or else one of its targets. If describe is set, it
+// also describes the choice when there was one to make.
+func (ctx ResolveContext) pick(l *link.Link, describe bool) (string, string) {
+	t := ctx.Time
+	if t.IsZero() {
+		t = link.Now()
+	}
+	if i, ok := l.ActiveRule(t); ok {
+		if !describe {
+			return l.Schedule[i].URL, ""
+		}
+		return l.Schedule[i].URL, fmt.Sprintf("schedule rule %d (%s)", i+1, l.Schedule[i])
+	}
+
+	targets := l.URLs()
+	if len(targets) == 1 {
+		return targets[0], ""
+	}
+	target := targets[0]
+	if ctx.Pick != nil {
+		target = ctx.Pick(l)
+	}
+	if !describe {
+		return target, ""
+	}
+	return target, fmt.Sprintf("target %d of %d", slices.Index(targets, target)+1, len(targets))
+}
+
+// redirectsScheme reports whether redirects to a non-HTTP scheme are allowed
+func (ctx ResolveContext) redirectsScheme(scheme string) bool {
+	return slices.ContainsFunc(ctx.RedirectSchemes, func(allowed string) bool {
+		return strings.EqualFold(allowed, scheme)
+	})
+}
@@ -0,0 +1,345 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkarpinos/golink/golinkrpc"
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// errUnauthorized is returned for writes without the admin token from
+// another machine
+var errUnauthorized = errors.New("unauthorized")
+
+// Limits on RPC connections, like the HTTP server's timeouts
+const (
+	rpcIdleTimeout    = 120 * time.Second // Longest wait for (and to read) the next request
+	rpcWriteTimeout   = 10 * time.Second  // Longest time to write a reply
+	rpcMaxMessageSize = 1 << 20           // Bytes read for one request
+)
+
+// rpcListener serves the JSON-RPC service on its own port and tracks open
+// connections so shutdown can close them. It is created with the server, so
+// Shutdown can close it while Start is still starting it.
+type rpcListener struct {
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	closed   bool
+}
+
+// newRPCListener creates a listener that isn't listening yet
+func newRPCListener() *rpcListener {
+	return &rpcListener{conns: make(map[net.Conn]bool)}
+}
+
+// startRPC listens on the RPC port and serves connections in the background
+func (s *Server) startRPC() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.opts.RPCPort))
+	if err != nil {
+		return fmt.Errorf("rpc: %w", err)
+	}
+
+	s.rpc.mutex.Lock()
+	if s.rpc.closed {
+		s.rpc.mutex.Unlock()
+		listener.Close()
+		return nil
+	}
+	s.rpc.listener = listener
+	s.rpc.mutex.Unlock()
+	fmt.Printf("JSON-RPC service listening on port %d\n", s.opts.RPCPort)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Printf("Error accepting RPC connection: %v", err)
+				}
+				return
+			}
+			go s.serveRPCConn(conn)
+		}
+	}()
+	return nil
+}
+
+// serveRPCConn serves one connection. Each gets its own service so writes
+// can be authorized by the connection's peer, like the admin endpoints.
+func (s *Server) serveRPCConn(conn net.Conn) {
+	s.rpc.mutex.Lock()
+	if s.rpc.closed {
+		s.rpc.mutex.Unlock()
+		conn.Close()
+		return
+	}
+	s.rpc.conns[conn] = true
+	s.rpc.mutex.Unlock()
+	defer func() {
+		s.rpc.mutex.Lock()
+		delete(s.rpc.conns, conn)
+		s.rpc.mutex.Unlock()
+	}()
+
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	ip := net.ParseIP(host)
+
+	srv := rpc.NewServer()
+	srv.RegisterName(golinkrpc.ServiceName, &rpcService{s: s, local: ip != nil && ip.IsLoopback()})
+	srv.ServeCodec(newRPCCodec(conn))
+}
+
+// close stops accepting connections and closes the open ones
+func (r *rpcListener) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	if r.listener != nil {
+		r.listener.Close()
+	}
+	for conn := range r.conns {
+		conn.Close()
+	}
+}
+
+// rpcCodec is the JSON-RPC codec with deadlines and a size limit for each
+// request, so a client can't hold a connection or memory indefinitely
+type rpcCodec struct {
+	rpc.ServerCodec
+	conn net.Conn
+	body *io.LimitedReader
+}
+
+// newRPCCodec wraps conn in a limited JSON-RPC codec
+func newRPCCodec(conn net.Conn) *rpcCodec {
+	body := &io.LimitedReader{R: conn, N: rpcMaxMessageSize}
+	rwc := struct {
+		io.Reader
+		io.Writer
+		io.Closer
+	}{body, conn, conn}
+	return &rpcCodec{ServerCodec: jsonrpc.NewServerCodec(rwc), conn: conn, body: body}
+}
+
+// ReadRequestHeader reads the next request, which jsonrpc decodes whole,
+// within the idle timeout and size limit
+func (c *rpcCodec) ReadRequestHeader(r *rpc.Request) error {
+	c.conn.SetReadDeadline(time.Now().Add(rpcIdleTimeout))
+	c.body.N = rpcMaxMessageSize
+	return c.ServerCodec.ReadRequestHeader(r)
+}
+
+// WriteResponse writes a reply within the write timeout
+func (c *rpcCodec) WriteResponse(r *rpc.Response, body any) error {
+	c.conn.SetWriteDeadline(time.Now().Add(rpcWriteTimeout))
+	return c.ServerCodec.WriteResponse(r, body)
+}
+
+// rpcService implements the methods described in package golinkrpc
+type rpcService struct {
+	s     *Server
+	local bool // The connection comes from this machine
+}
+
+// authorize checks a write request, with the same rule as authorizedAdmin
+func (r *rpcService) authorize(token string) error {
+	if r.s.opts.AdminToken != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(r.s.opts.AdminToken)) != 1 {
+			return errUnauthorized
+		}
+		return nil
+	}
+	if !r.local {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// Get replies with a link
+func (r *rpcService) Get(args *golinkrpc.GetArgs, reply *golinkrpc.Link) error {
+	l, err := r.s.storage.Get(link.NormalizeAlias(args.Alias, r.s.opts.AliasSpace))
+	if err != nil {
+		return err
+	}
+	*reply = toRPCLink(l)
+	return nil
+}
+
+// List replies with all links, or those in a category, sorted by alias
+func (r *rpcService) List(args *golinkrpc.ListArgs, reply *golinkrpc.ListReply) error {
+	reply.Links = []golinkrpc.Link{}
+	r.s.storage.ForEach(func(l *link.Link) bool {
+		if args.Category == "" || strings.EqualFold(l.Category, args.Category) {
+			reply.Links = append(reply.Links, toRPCLink(l))
+		}
+		return true
+	})
+	sort.Slice(reply.Links, func(i, j int) bool {
+		return reply.Links[i].Alias < reply.Links[j].Alias
+	})
+	return nil
+}
+
+// Create adds a link, validated like golink add
+func (r *rpcService) Create(args *golinkrpc.WriteArgs, reply *golinkrpc.Link) error {
+	if err := r.authorize(args.Token); err != nil {
+		return err
+	}
+
+	l := fromRPCLink(args.Link, r.s.opts.AliasSpace)
+	created := link.NewLink(l.Alias, l.URL, l.Description, l.Category)
+	l.CreatedAt, l.UpdatedAt = created.CreatedAt, created.UpdatedAt
+	if err := r.validate(l); err != nil {
+		return err
+	}
+	if err := r.s.storage.Create(l); err != nil {
+		return err
+	}
+	*reply = toRPCLink(l)
+	return nil
+}
+
+// Update replaces an existing link, keeping its creation time
+func (r *rpcService) Update(args *golinkrpc.WriteArgs, reply *golinkrpc.Link) error {
+	if err := r.authorize(args.Token); err != nil {
+		return err
+	}
+
+	l := fromRPCLink(args.Link, r.s.opts.AliasSpace)
+	existing, err := r.s.storage.Get(l.Alias)
+	if err != nil {
+		return err
+	}
+	l.CreatedAt = existing.CreatedAt
+	if err := r.validate(l); err != nil {
+		return err
+	}
+	if err := r.s.storage.Update(l); err != nil {
+		return err
+	}
+	*reply = toRPCLink(l)
+	return nil
+}
+
+// Delete removes a link
+func (r *rpcService) Delete(args *golinkrpc.DeleteArgs, reply *golinkrpc.Link) error {
+	if err := r.authorize(args.Token); err != nil {
+		return err
+	}
+
+	alias := link.NormalizeAlias(args.Alias, r.s.opts.AliasSpace)
+	l, err := r.s.storage.Get(alias)
+	if err != nil {
+		return err
+	}
+	if err := r.s.storage.Delete(alias); err != nil {
+		return err
+	}
+	*reply = toRPCLink(l)
+	return nil
+}
+
+// Resolve resolves an alias the way the server's redirects do, including
+// pattern links and the server's scheme and host checks. Like golink
+// resolve, it uses the first of a link's targets, so calls don't advance
+// the server's round-robin.
+func (r *rpcService) Resolve(args *golinkrpc.ResolveArgs, reply *golinkrpc.ResolveReply) error {
+	ctx := r.s.resolveContext(args.Params)
+	ctx.Pick = nil
+	ctx.StrictParams = ctx.StrictParams || args.Strict
+
+	res, err := Resolve(args.Alias, ctx)
+	if err != nil {
+		return err
+	}
+	reply.Hops, reply.URL = res.Hops, res.URL
+	return nil
+}
+
+// validate checks a link sent over RPC before it is stored
+func (r *rpcService) validate(l *link.Link) error {
+	if err := link.ValidateAlias(l.Alias); err != nil {
+		return err
+	}
+	if IsReserved(l.Alias) {
+		return fmt.Errorf("alias %q is reserved by the server", l.Alias)
+	}
+	if err := link.ValidateRedirect(l.Redirect); err != nil {
+		return err
+	}
+	if err := l.ValidateURL(r.s.opts.LinkSchemes); err != nil {
+		return err
+	}
+
+	// golink add only warns, but an RPC client has nowhere to show a
+	// warning, and the server would refuse to redirect there anyway
+	targets := l.URLs()
+	for _, rule := range l.Schedule {
+		targets = append(targets, rule.URL)
+	}
+	for _, target := range targets {
+		if !r.s.opts.AllowedRedirectHosts.Allows(target) {
+			return fmt.Errorf("%s is not in allowed_redirect_hosts", target)
+		}
+	}
+
+	if l.Pattern != "" {
+		return l.ValidatePattern()
+	}
+	return nil
+}
+
+// toRPCLink converts a stored link to its RPC form
+func toRPCLink(l *link.Link) golinkrpc.Link {
+	out := golinkrpc.Link{
+		Alias:        l.Alias,
+		URL:          l.URL,
+		Targets:      l.Targets,
+		TargetPolicy: l.TargetPolicy,
+		Description:  l.Description,
+		Category:     l.Category,
+		Redirect:     l.Redirect,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+		Pattern:      l.Pattern,
+	}
+	for _, rule := range l.Schedule {
+		out.Schedule = append(out.Schedule, golinkrpc.ScheduleRule(rule))
+	}
+	return out
+}
+
+// fromRPCLink converts a link received over RPC, normalizing its alias
+func fromRPCLink(in golinkrpc.Link, aliasSpace string) *link.Link {
+	l := &link.Link{
+		Alias:        link.NormalizeAlias(in.Alias, aliasSpace),
+		URL:          in.URL,
+		Targets:      in.Targets,
+		TargetPolicy: in.TargetPolicy,
+		Description:  in.Description,
+		Category:     in.Category,
+		Redirect:     in.Redirect,
+		Pattern:      in.Pattern,
+	}
+	// The URL is the first target for links with several
+	if len(l.Targets) > 0 {
+		l.URL = l.Targets[0]
+	}
+	for _, rule := range in.Schedule {
+		l.Schedule = append(l.Schedule, link.ScheduleRule(rule))
+	}
+	return l
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/bkarpinos/golink/golinkrpc"
+)
+
+// dialTestRPC serves s's RPC service on a loopback port and returns a
+// client connected to it
+func dialTestRPC(t *testing.T, s *Server) *golinkrpc.Client {
+	t.Helper()
+	if s.rpc == nil {
+		s.rpc = newRPCListener()
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		s.rpc.close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					t.Errorf("accept: %v", err)
+				}
+				return
+			}
+			go s.serveRPCConn(conn)
+		}
+	}()
+
+	client, err := golinkrpc.Dial(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRPCRoundTrip(t *testing.T) {
+	s := newTestServer(t, Options{AliasSpace: "-"})
+	client := dialTestRPC(t, s)
+
+	created, err := client.Create(golinkrpc.Link{Alias: "team docs", URL: "https://example.com/docs", Category: "eng"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Alias != "team-docs" || created.CreatedAt.IsZero() {
+		t.Errorf("Create = alias %q created %v, want the normalized alias and a creation time", created.Alias, created.CreatedAt)
+	}
+	if _, err := client.Create(golinkrpc.Link{Alias: "handbook", URL: "alias:team-docs"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Create(golinkrpc.Link{Alias: "team-docs", URL: "https://example.com/other"}); err == nil {
+		t.Error("Create of an existing alias succeeded, want an error")
+	}
+
+	got, err := client.Get("team docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != "https://example.com/docs" || got.Category != "eng" {
+		t.Errorf("Get = %+v, want the created link", got)
+	}
+
+	updated, err := client.Update(golinkrpc.Link{Alias: "team-docs", URL: "https://example.com/docs/{page}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Update changed the creation time from %v to %v", created.CreatedAt, updated.CreatedAt)
+	}
+
+	res, err := client.Resolve("handbook", map[string]string{"page": "intro"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.URL != "https://example.com/docs/intro" || len(res.Hops) != 2 {
+		t.Errorf("Resolve = %s via %v, want https://example.com/docs/intro via handbook and team-docs", res.URL, res.Hops)
+	}
+	if _, err := client.Resolve("handbook", nil, true); err == nil {
+		t.Error("strict Resolve with an unfilled placeholder succeeded, want an error")
+	}
+
+	links, err := client.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].Alias != "handbook" || links[1].Alias != "team-docs" {
+		t.Errorf("List = %+v, want handbook and team-docs", links)
+	}
+
+	if _, err := client.Delete("team-docs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get("team-docs"); err == nil {
+		t.Error("Get after Delete succeeded, want an error")
+	}
+	if _, err := client.Resolve("handbook", nil, false); err == nil {
+		t.Error("Resolve through a deleted link succeeded, want an error")
+	}
+}
+
+func TestRPCAuthorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		token      string
+		wantErr    bool
+	}{
+		{"local without an admin token", "", "", false},
+		{"right token", "secret", "secret", false},
+		{"wrong token", "secret", "guess", true},
+		{"missing token", "secret", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, Options{AdminToken: tt.adminToken})
+			client := dialTestRPC(t, s)
+			client.Token = tt.token
+
+			_, err := client.Create(golinkrpc.Link{Alias: "docs", URL: "https://example.com/docs"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if s.storage.Count() != 0 {
+				t.Error("an unauthorized Create stored the link")
+			}
+			// Reads don't need the token
+			if _, err := client.List(""); err != nil {
+				t.Errorf("List = %v, want no error", err)
+			}
+		})
+	}
+}
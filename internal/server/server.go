@@ -2,40 +2,141 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"html"
+	"net"
 	"net/http"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bkarpinos/golink/internal/link"
 	"github.com/bkarpinos/golink/internal/storage"
+	"github.com/bkarpinos/golink/internal/tree"
 )
 
+// Options configures a Server
+type Options struct {
+	Port        int    // Port to listen on
+	NotFoundURL string // URL to redirect to when an alias doesn't exist (optional)
+
+	DisplaySeparator string   // Group aliases on the homepage by this separator (optional)
+	StrictParams     bool     // Reject redirects that leave URL template parameters unfilled
+	AliasSpace       string   // Replace spaces in requested aliases with this (e.g. "-"), or "" to leave them
+	RedirectSchemes  []string // Non-HTTP schemes (e.g. "slack") that links may redirect to (the redirect_schemes config)
+
+	AllowedRedirectHosts HostAllowlist // Hosts web targets may redirect to (empty allows all)
+	ProxyAutoConfig      bool          // Serve a PAC file at /proxy.pac routing go/* to this server
+	Favicons             bool          // Show site favicons on the homepage via /favicon-proxy
+
+	Categories map[string]CategoryStyle // Homepage color and icon by lowercased category name
+
+	RedirectMode string         // Default redirect mode for links without one (link.RedirectFound or link.RedirectBeacon)
+	BeaconURL    string         // Analytics URL fired by the beacon page; {alias} is replaced with the alias
+	Location     *time.Location // Timezone link schedules are evaluated in (nil for local time)
+
+	TrustedProxies []*net.IPNet // Proxies allowed to set X-Forwarded-For/X-Real-IP
+	AdminToken     string       // Bearer token for admin endpoints (loopback only if empty)
+
+	LogSample    float64  // Fraction of successful requests to log (errors and not-founds are always logged)
+	LogSkipPaths []string // Paths whose successful requests are never logged
+	LogLevel     string   // Which requests to log: LogDebug, LogInfo (default), LogWarn or LogError
+
+	BlockPrivate bool         // Refuse server-side fetches of private/loopback/link-local addresses
+	AllowPrivate []*net.IPNet // Private ranges exempt from BlockPrivate
+
+	IdleShutdown    time.Duration // Signal Idle after this long without requests (0 disables)
+	IdleCountHealth bool          // Count health check requests as activity
+
+	MonitorInterval    time.Duration // How often to check link targets are reachable (0 disables)
+	MonitorTimeout     time.Duration // Timeout for each reachability check
+	MonitorConcurrency int           // Maximum number of checks running at once
+
+	EventsURL      string // Webhook to POST redirect events to (optional)
+	EventsMetadata bool   // Include client IP, user agent and referer in events
+
+	RPCPort     int      // Port for the JSON-RPC service (0 disables)
+	LinkSchemes []string // Non-HTTP schemes links created over RPC may use (the link_schemes config)
+}
+
 // Server represents the HTTP server for go links
 type Server struct {
 	storage  *storage.JSONStorage
 	server   *http.Server
 	baseURL  string
 	notFound string
+	missing  *notFoundTracker
+	hits     *hitCounter
+	targets  *targetPicker
+	events   *eventSink
+	monitor  *monitor
+	idle     *idleTracker
+	favicons *faviconCache
+	rpc      *rpcListener
+	resolver *net.Resolver // Resolves hosts the server fetches from (nil uses the system's)
+	ready    chan struct{} // Closed once the HTTP listener is bound
+	sample   atomic.Uint64 // math.Float64bits of the log sample rate
+	opts     Options
 }
 
 // NewServer creates a new go links HTTP server
-func NewServer(storage *storage.JSONStorage, port int, notFoundURL string) *Server {
-	baseURL := fmt.Sprintf("http://localhost:%d", port)
+func NewServer(storage *storage.JSONStorage, opts Options) *Server {
+	baseURL := fmt.Sprintf("http://localhost:%d", opts.Port)
 
-	return &Server{
+	s := &Server{
 		storage:  storage,
 		baseURL:  baseURL,
-		notFound: notFoundURL,
+		notFound: opts.NotFoundURL,
+		missing:  newNotFoundTracker(maxNotFoundEntries),
+		hits:     newHitCounter(),
+		targets:  newTargetPicker(),
+		opts:     opts,
+		ready:    make(chan struct{}),
 		server: &http.Server{
-			Addr:         fmt.Sprintf(":%d", port),
+			Addr:         fmt.Sprintf(":%d", opts.Port),
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  120 * time.Second,
 		},
 	}
+
+	if opts.EventsURL != "" {
+		s.events = newEventSink(opts.EventsURL)
+	}
+
+	if opts.RPCPort > 0 {
+		s.rpc = newRPCListener()
+	}
+
+	s.SetLogSampleRate(opts.LogSample)
+
+	if opts.Favicons {
+		s.favicons = newFaviconCache(s.fetchClient(5 * time.Second))
+	}
+
+	if opts.IdleShutdown > 0 {
+		s.idle = newIdleTracker(opts.IdleShutdown, opts.IdleCountHealth)
+	}
+
+	if opts.MonitorInterval > 0 {
+		s.monitor = newMonitor(storage, s.fetchClient(opts.MonitorTimeout), opts.MonitorInterval, opts.MonitorConcurrency)
+	}
+
+	return s
+}
+
+// reservedPaths are the server's own routes, which shadow links with the
+// same alias
+var reservedPaths = []string{"info", "healthz", "proxy.pac", "favicon-proxy"}
+
+// IsReserved reports whether an alias can't be reached because the server
+// handles that path itself
+func IsReserved(alias string) bool {
+	return slices.Contains(reservedPaths, alias) || alias == "api" || strings.HasPrefix(alias, "api/")
 }
 
 // Start begins serving go links
@@ -48,23 +149,108 @@ func (s *Server) Start() error {
 	// Add an information page at /info
 	mux.HandleFunc("/info", s.handleInfo)
 
-	s.server.Handler = logMiddleware(mux)
+	// Health check for load balancers and supervisors
+	mux.HandleFunc(healthPath, s.handleHealth)
+
+	// Most requested aliases that don't exist yet
+	mux.HandleFunc("/api/suggestions", s.handleSuggestions)
+
+	// Ranked link search
+	mux.HandleFunc("/api/search", s.handleSearch)
+
+	// Reachability of link targets, when the monitor is enabled
+	mux.HandleFunc("/api/status", s.handleStatus)
+
+	// Traffic counts since startup, for golink top
+	mux.HandleFunc(statsPath, s.handleStats)
+
+	// Force a reload of links.json
+	mux.HandleFunc("/api/reload", s.handleReload)
+
+	// Proxy auto-config file for resolving go/ without the hosts file
+	if s.opts.ProxyAutoConfig {
+		mux.HandleFunc("/proxy.pac", s.handleProxyAutoConfig)
+	}
+
+	// Cached site icons for the homepage
+	if s.favicons != nil {
+		mux.HandleFunc("/favicon-proxy", s.handleFaviconProxy)
+	}
+
+	var handler http.Handler = s.hits.middleware(mux)
+	if s.idle != nil {
+		handler = s.idle.middleware(handler)
+	}
+	s.server.Handler = s.logMiddleware(handler)
+
+	if s.rpc != nil {
+		if err := s.startRPC(); err != nil {
+			return err
+		}
+	}
+
+	// Bind before announcing the server, so Ready only fires once
+	// connections will be accepted
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	close(s.ready)
 
 	fmt.Printf("Go Links server started at %s\n", s.baseURL)
 	fmt.Printf("Press Ctrl+C to stop the server\n")
 
-	return s.server.ListenAndServe()
+	return s.server.Serve(listener)
+}
+
+// Ready returns a channel that is closed once the server is listening
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// URL returns the server's base URL
+func (s *Server) URL() string {
+	return s.baseURL
+}
+
+// Idle returns a channel that is closed once the server has gone without
+// requests for the IdleShutdown duration. It is nil, and so never ready, when
+// idle shutdown is disabled.
+func (s *Server) Idle() <-chan struct{} {
+	if s.idle == nil {
+		return nil
+	}
+	return s.idle.idle
 }
 
 // Shutdown gracefully stops the server
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	err := s.server.Shutdown(ctx)
+
+	if s.rpc != nil {
+		s.rpc.close()
+	}
+
+	if s.monitor != nil {
+		s.monitor.close()
+	}
+
+	// Deliver any queued events once no more redirects can come in
+	if s.events != nil {
+		if eventsErr := s.events.close(ctx); err == nil {
+			err = eventsErr
+		}
+	}
+
+	return err
 }
 
 // handleRedirect processes go link redirects
 func (s *Server) handleRedirect(w http.ResponseWriter, r *http.Request) {
-	// Extract the go link alias from the path
-	alias := strings.TrimPrefix(r.URL.Path, "/")
+	// Extract the go link alias from the path. Dots are ordinary path
+	// characters, so aliases like "k8s.dev" or "report.pdf" pass through
+	// as-is; spaces (go/team%20meeting) are normalized like on the CLI.
+	alias := link.NormalizeAlias(strings.TrimPrefix(r.URL.Path, "/"), s.opts.AliasSpace)
 
 	// Empty path or root
 	if alias == "" {
@@ -72,9 +258,14 @@ func (s *Server) handleRedirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Look up the link
-	link, err := s.storage.Get(alias)
-	if err != nil {
+	res, err := resolve(alias, s.resolveContext(queryParams(r)), false)
+	if errors.Is(err, storage.ErrNotFound) {
+		// Browsers request /favicon.ico on their own; that's not demand for a link
+		if alias != "favicon.ico" {
+			s.missing.record(alias)
+		}
+		markNotFound(w)
+
 		if s.notFound != "" {
 			// Redirect to the configured "not found" URL if specified
 			http.Redirect(w, r, s.notFound, http.StatusFound)
@@ -85,14 +276,113 @@ func (s *Server) handleRedirect(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Go link not found: %s", alias), http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		switch {
+		case errors.Is(err, link.ErrAliasLoop):
+			http.Error(w, fmt.Sprintf("Go link %v", err), http.StatusLoopDetected)
+		case errors.Is(err, link.ErrMissingAlias):
+			http.Error(w, fmt.Sprintf("Go link %v", err), http.StatusNotFound)
+		case errors.Is(err, ErrTargetNotAllowed):
+			http.Error(w, fmt.Sprintf("Go link %v", err), http.StatusForbidden)
+		default:
+			// Missing or invalid parameters
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	requested, target := res.requested, res.target
+
+	// Log which target was chosen when there was a choice or a pattern
+	if l := res.final; len(l.Targets) > 1 || len(l.Schedule) > 0 || l.Pattern != "" {
+		markTarget(w, target)
+	}
+
+	// Redirect to the target URL, through the beacon page if enabled
+	if s.useBeacon(requested) {
+		s.writeBeaconPage(w, requested.Alias, target)
+	} else {
+		redirect(w, r, target)
+	}
+	s.hits.record(requested.Alias)
+
+	if s.events != nil {
+		s.events.send(s.newEvent(r, requested.Alias, target))
+	}
+}
+
+// redirect sends a 302 to target. Absolute web URLs skip http.Redirect,
+// whose URL parsing and HTML body only matter for relative targets and
+// clients that don't follow redirects; this is the hottest path in the server.
+func redirect(w http.ResponseWriter, r *http.Request, target string) {
+	if scheme := link.URLScheme(target); scheme != "http" && scheme != "https" {
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+	w.Header()["Location"] = []string{target}
+	w.WriteHeader(http.StatusFound)
+}
+
+// queryParams returns the placeholder values in r's query string, or nil
+// if it has none
+func queryParams(r *http.Request) map[string]string {
+	if r.URL.RawQuery == "" {
+		return nil
+	}
+	params := make(map[string]string)
+	for name, values := range r.URL.Query() {
+		params[name] = values[0]
+	}
+	return params
+}
+
+// resolveContext is the context the server resolves requests in, with the
+// given placeholder values. Targets are picked in turn.
+func (s *Server) resolveContext(params map[string]string) ResolveContext {
+	loc := s.opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	return ResolveContext{
+		Storage:      s.storage,
+		Params:       params,
+		StrictParams: s.opts.StrictParams,
+		AliasSpace:   s.opts.AliasSpace,
+
+		Time: link.Now().In(loc),
+		Pick: s.targets.pick,
+
+		RedirectSchemes:      s.opts.RedirectSchemes,
+		AllowedRedirectHosts: s.opts.AllowedRedirectHosts,
+	}
+}
+
+// newEvent builds the webhook event for a redirect
+func (s *Server) newEvent(r *http.Request, alias, target string) Event {
+	ev := Event{
+		Alias:     alias,
+		Target:    target,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if s.opts.EventsMetadata {
+		ev.ClientIP = s.clientIP(r)
+		ev.UserAgent = r.UserAgent()
+		ev.Referer = r.Referer()
+	}
 
-	// Redirect to the target URL
-	http.Redirect(w, r, link.URL, http.StatusFound)
+	return ev
 }
 
 // handleRootPage shows a simple homepage with usage instructions
 func (s *Server) handleRootPage(w http.ResponseWriter, r *http.Request) {
-	links := s.storage.List()
+	// Group links by category, and by alias prefix if a separator is
+	// configured, straight from the store rather than copying a list first
+	builder := tree.NewBuilder(s.opts.DisplaySeparator)
+	s.storage.ForEach(func(l *link.Link) bool {
+		builder.Add(l)
+		return true
+	})
+	categories := builder.Roots()
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -106,75 +396,61 @@ func (s *Server) handleRootPage(w http.ResponseWriter, r *http.Request) {
         pre { white-space: pre; line-height: 1.5; }
         a { text-decoration: none; color: #0066cc; }
         a:hover { text-decoration: underline; }
+        #search { width: 100%%; padding: 6px; font: inherit; box-sizing: border-box; }
+        #results { list-style: none; padding: 0; }
+        #results li { margin: 4px 0; }
+        #results span { color: #666; }
 			</style>
 	</head>
 	<body>
 			<h1>Go Links Service</h1>
 			<p>Use this service by navigating to <code>%s/&lt;alias&gt;</code></p>
+			<input id="search" type="search" placeholder="Search links" autofocus>
+			<ul id="results"></ul>
+			<script>
+				const search = document.getElementById("search");
+				const results = document.getElementById("results");
+				const escape = s => s.replace(/[&<>"']/g, c => "&#" + c.charCodeAt(0) + ";");
+				search.addEventListener("input", async () => {
+					const q = search.value.trim();
+					if (!q) { results.innerHTML = ""; return; }
+					const resp = await fetch("/api/search?highlight=true&q=" + encodeURIComponent(q));
+					if (!resp.ok || search.value.trim() !== q) return;
+					results.innerHTML = (await resp.json()).map(r => {
+						const h = r.highlights || {};
+						const alias = h.alias || escape(r.link.alias);
+						const desc = h.description || escape(r.link.description || "");
+						return '<li><a href="/' + encodeURI(r.link.alias) + '">' + alias + '</a> <span>' + desc + '</span></li>';
+					}).join("");
+				});
+			</script>
 			<h2>Available Links</h2>`, s.baseURL)
 
-	if len(links) == 0 {
+	if len(categories) == 0 {
 		fmt.Fprintf(w, "<p>No links available. Add some using the CLI tool.</p>")
 	} else {
-		// Group links by category
-		categories := make(map[string][]*link.Link)
-		for _, l := range links {
-			cat := l.Category
-			if cat == "" {
-				cat = "uncategorized"
-			} else {
-				cat = strings.ToLower(cat) // Ensure lowercase categories
-			}
-			categories[cat] = append(categories[cat], l)
-		}
-
-		// Sort categories for consistent display
-		sortedCats := make([]string, 0, len(categories))
-		for cat := range categories {
-			sortedCats = append(sortedCats, cat)
-		}
-		sort.Strings(sortedCats)
-
 		// Start the pre-formatted tree output
 		fmt.Fprintf(w, "<pre>")
 
-		// Create the tree structure
-		for i, cat := range sortedCats {
-			catLinks := categories[cat]
-			isLastCat := i == len(sortedCats)-1
-
-			// Category prefix
-			if isLastCat {
-				fmt.Fprintf(w, "└── %s\n", cat)
-			} else {
-				fmt.Fprintf(w, "├── %s\n", cat)
+		tree.Walk(categories, func(prefix string, n *tree.Node) {
+			if slices.Contains(categories, n) {
+				fmt.Fprintf(w, "%s%s\n", prefix, s.categoryHeading(n.Name))
+				return
 			}
-
-			// Sort links within each category
-			sort.Slice(catLinks, func(i, j int) bool {
-				return catLinks[i].Alias < catLinks[j].Alias
-			})
-
-			// Links under this category
-			for j, l := range catLinks {
-				isLastLink := j == len(catLinks)-1
-
-				// Link prefix based on position
-				if isLastCat {
-					if isLastLink {
-						fmt.Fprintf(w, "    └── %s → <a href=\"%s\">%s</a>\n", l.Alias, l.URL, l.URL)
-					} else {
-						fmt.Fprintf(w, "    ├── %s → <a href=\"%s\">%s</a>\n", l.Alias, l.URL, l.URL)
-					}
-				} else {
-					if isLastLink {
-						fmt.Fprintf(w, "│   └── %s → <a href=\"%s\">%s</a>\n", l.Alias, l.URL, l.URL)
-					} else {
-						fmt.Fprintf(w, "│   ├── %s → <a href=\"%s\">%s</a>\n", l.Alias, l.URL, l.URL)
-					}
-				}
+			if n.Link == nil {
+				fmt.Fprintf(w, "%s%s\n", prefix, n.Name)
+				return
 			}
-		}
+			if n.Link.Pattern != "" {
+				fmt.Fprintf(w, "%s%s → %s (pattern %s)\n", prefix, n.Name, html.EscapeString(n.Link.URL), html.EscapeString(n.Link.Pattern))
+				return
+			}
+			icon := ""
+			if s.favicons != nil {
+				icon = faviconImg(n.Link.URL)
+			}
+			fmt.Fprintf(w, "%s%s%s → <a href=\"%s\">%s</a>\n", prefix, icon, n.Name, n.Link.URL, n.Link.URL)
+		})
 
 		fmt.Fprintf(w, "</pre>")
 	}
@@ -186,7 +462,7 @@ func (s *Server) handleRootPage(w http.ResponseWriter, r *http.Request) {
 
 // handleInfo displays information about the go links service
 func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
-	links := s.storage.List()
+	missing := s.missing.top(10)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -200,6 +476,8 @@ func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
         .stats { display: flex; gap: 20px; }
         .stat-box { flex: 1; padding: 15px; background: #f5f5f5; border-radius: 5px; text-align: center; }
         .stat-number { font-size: 24px; font-weight: bold; margin: 10px 0; }
+        table { border-collapse: collapse; }
+        td, th { padding: 4px 12px; text-align: left; }
     </style>
 </head>
 <body>
@@ -215,25 +493,139 @@ func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
         <li>Base URL: %s</li>
         <li>Storage: JSON File</li>
     </ul>
+    <h2>Most Requested Missing Links</h2>`, s.storage.Count(), s.baseURL)
+
+	if len(missing) == 0 {
+		fmt.Fprintf(w, `
+    <p>No missing links requested since the server started.</p>`)
+	} else {
+		fmt.Fprintf(w, `
+    <table>
+        <tr><th>Alias</th><th>Requests</th></tr>`)
+		for _, m := range missing {
+			fmt.Fprintf(w, `
+        <tr><td>%s</td><td>%d</td></tr>`, html.EscapeString(m.Alias), m.Count)
+		}
+		fmt.Fprintf(w, `
+    </table>`)
+	}
+
+	if s.monitor != nil {
+		var unreachable []LinkStatus
+		for _, status := range s.monitor.statuses() {
+			if status.Status == StatusUnreachable {
+				unreachable = append(unreachable, status)
+			}
+		}
+
+		fmt.Fprintf(w, `
+    <h2>Unreachable Links</h2>`)
+		if len(unreachable) == 0 {
+			fmt.Fprintf(w, `
+    <p>All checked links are reachable.</p>`)
+		} else {
+			fmt.Fprintf(w, `
+    <table>
+        <tr><th>Alias</th><th>URL</th><th>Problem</th><th>Checked</th></tr>`)
+			for _, u := range unreachable {
+				problem := u.Error
+				if problem == "" {
+					problem = http.StatusText(u.Code)
+				}
+				fmt.Fprintf(w, `
+        <tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>`,
+					html.EscapeString(u.Alias), html.EscapeString(u.URL), html.EscapeString(problem), u.CheckedAt.Format("2006-01-02 15:04"))
+			}
+			fmt.Fprintf(w, `
+    </table>`)
+		}
+	}
+
+	fmt.Fprintf(w, `
     <p><a href="/">Back to home</a></p>
 </body>
-</html>`, len(links), s.baseURL)
+</html>`)
 }
 
-// logMiddleware logs incoming requests
-func logMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+// handleSuggestions returns the most requested missing aliases as JSON
+func (s *Server) handleSuggestions(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
 
-		// Call the next handler
-		next.ServeHTTP(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.missing.top(limit))
+}
 
-		// Log the request
-		log.Printf(
-			"%s %s %s",
-			r.Method,
-			r.RequestURI,
-			time.Since(start),
-		)
-	})
+// Result limits for /api/search
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 100
+)
+
+// handleSearch returns links matching the q parameter as JSON, best first.
+// limit caps the number of results (at most maxSearchLimit) and
+// highlight=true adds HTML snippets marking where the query matched.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	highlight := false
+	if v := query.Get("highlight"); v != "" {
+		var err error
+		if highlight, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "highlight must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	results := s.storage.Search(q, limit)
+	if highlight {
+		for _, result := range results {
+			result.Highlight()
+		}
+	}
+	if results == nil {
+		results = []*storage.SearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleHealth reports that the server is up
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// handleStatus returns the latest reachability check results as JSON
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if s.monitor == nil {
+		http.Error(w, "reachability monitor is disabled", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.monitor.statuses())
 }
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bkarpinos/golink/internal/link"
+	"github.com/bkarpinos/golink/internal/storage"
+)
+
+// newTestServer creates a server over a temporary storage holding links
+func newTestServer(tb testing.TB, opts Options, links ...*link.Link) *Server {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "links.json")
+	if err := storage.WriteFile(path, links, false); err != nil {
+		tb.Fatal(err)
+	}
+	store, err := storage.NewJSONStorage(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return NewServer(store, opts)
+}
+
+func TestResolveSchedule(t *testing.T) {
+	meeting := link.NewLink("meeting", "https://meet.example.com/default", "", "")
+	meeting.Schedule = []link.ScheduleRule{
+		{URL: "https://meet.example.com/standup", Days: []string{"mon"}, From: "09:00", Until: "10:00"},
+		{URL: "https://meet.example.com/late", From: "22:00", Until: "06:00"},
+	}
+	standup := link.NewLink("standup", "alias:meeting", "", "")
+	s := newTestServer(t, Options{}, meeting, standup)
+
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	tests := []struct {
+		path string
+		at   time.Time
+		want string
+		rule string
+	}{
+		{"/meeting", time.Date(2026, 10, 12, 9, 15, 0, 0, time.UTC), "https://meet.example.com/standup", "link meeting, schedule rule 1 (mon 09:00-10:00)"},
+		{"/meeting", time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC), "https://meet.example.com/default", "link meeting"},
+		{"/meeting", time.Date(2026, 10, 13, 3, 0, 0, 0, time.UTC), "https://meet.example.com/late", "link meeting, schedule rule 2 (22:00-06:00)"},
+		{"/standup", time.Date(2026, 10, 12, 9, 15, 0, 0, time.UTC), "https://meet.example.com/standup", "link standup, alias meeting, schedule rule 1 (mon 09:00-10:00)"},
+
+		// 00:30 UTC is outside the window, but 09:30 in the server's timezone
+		{"/meeting", time.Date(2026, 10, 12, 0, 30, 0, 0, time.UTC).In(tokyo), "https://meet.example.com/standup", "link meeting, schedule rule 1 (mon 09:00-10:00)"},
+	}
+	for _, tt := range tests {
+		res, err := Resolve(tt.path, ResolveContext{Storage: s.storage, Time: tt.at})
+		if err != nil || res.URL != tt.want || res.Rule != tt.rule {
+			t.Errorf("Resolve(%s) at %s = %q, %q, %v; want %q, %q", tt.path, tt.at, res.URL, res.Rule, err, tt.want, tt.rule)
+		}
+	}
+}
+
+// TestResolveConsistent checks that redirects, Resolve and the RPC service
+// agree on where a path leads, and on what they refuse
+func TestResolveConsistent(t *testing.T) {
+	bug := link.NewLink("bug", "https://tracker.example.com/issue/$1", "", "")
+	bug.Pattern = `^bug/(\d+)$`
+	allowed, err := ParseHostAllowlist([]string{"*.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer(t, Options{AllowedRedirectHosts: allowed},
+		bug,
+		link.NewLink("search", "https://search.example.com/?q={q}", "", ""),
+		link.NewLink("find", "alias:search", "", ""),
+		link.NewLink("notes", "file:///home/me/notes.txt", "", ""),
+		link.NewLink("elsewhere", "https://example.org", "", ""),
+	)
+	client := dialTestRPC(t, s)
+
+	tests := []struct {
+		alias  string
+		params map[string]string
+		want   string // Empty when the target is refused
+		hops   []string
+	}{
+		{"bug/123", nil, "https://tracker.example.com/issue/123", []string{"bug"}},
+		{"search", map[string]string{"q": "go & rust"}, "https://search.example.com/?q=go+%26+rust", []string{"search"}},
+		{"find", map[string]string{"q": "golang"}, "https://search.example.com/?q=golang", []string{"find", "search"}},
+		{"notes", nil, "", nil},     // Scheme not allowed
+		{"elsewhere", nil, "", nil}, // Host not allowed
+	}
+	for _, tt := range tests {
+		query := url.Values{}
+		for k, v := range tt.params {
+			query.Set(k, v)
+		}
+		path := "/" + tt.alias
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+
+		res, err := Resolve(tt.alias, s.resolveContext(tt.params))
+		reply, rpcErr := client.Resolve(tt.alias, tt.params, false)
+		w := httptest.NewRecorder()
+		s.handleRedirect(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if tt.want == "" {
+			if !errors.Is(err, ErrTargetNotAllowed) {
+				t.Errorf("Resolve(%s) = %q, %v; want ErrTargetNotAllowed", tt.alias, res.URL, err)
+			}
+			if rpcErr == nil {
+				t.Errorf("RPC Resolve(%s) = %q, want an error", tt.alias, reply.URL)
+			}
+			if w.Code != http.StatusForbidden {
+				t.Errorf("GET %s: status %d, want %d", path, w.Code, http.StatusForbidden)
+			}
+			continue
+		}
+
+		if err != nil || res.URL != tt.want || !slices.Equal(res.Hops, tt.hops) {
+			t.Errorf("Resolve(%s) = %q, hops %q, %v; want %q, hops %q", tt.alias, res.URL, res.Hops, err, tt.want, tt.hops)
+		}
+		if rpcErr != nil || reply.URL != tt.want || !slices.Equal(reply.Hops, tt.hops) {
+			t.Errorf("RPC Resolve(%s) = %v, %v; want %q, hops %q", tt.alias, reply, rpcErr, tt.want, tt.hops)
+		}
+		if w.Code != http.StatusFound || w.Header().Get("Location") != tt.want {
+			t.Errorf("GET %s: status %d, Location %q; want %d, %q", path, w.Code, w.Header().Get("Location"), http.StatusFound, tt.want)
+		}
+	}
+}
+
+// benchmarkWriter is a ResponseWriter that keeps only the status and
+// headers, so benchmarks measure the handler rather than the recorder
+type benchmarkWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *benchmarkWriter) Header() http.Header         { return w.header }
+func (w *benchmarkWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *benchmarkWriter) WriteHeader(status int)      { w.status = status }
+
+// BenchmarkHandleRedirect serves a 302 for a plain link from catalogs of
+// different sizes. The target is under a microsecond and a handful of
+// allocations per redirect at every size, since lookups are a single map
+// access that doesn't copy the link.
+func BenchmarkHandleRedirect(b *testing.B) {
+	for _, n := range []int{10, 1_000, 100_000} {
+		links := make([]*link.Link, n)
+		for i := range links {
+			links[i] = link.NewLink(fmt.Sprintf("link-%06d", i), fmt.Sprintf("https://example.com/docs/%d", i), "", "")
+		}
+		s := newTestServer(b, Options{}, links...)
+		r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/link-%06d", n/2), nil)
+
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				w := &benchmarkWriter{header: make(http.Header, 1)}
+				s.handleRedirect(w, r)
+				if w.status != http.StatusFound {
+					b.Fatalf("status = %d, want %d", w.status, http.StatusFound)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRedirectScheme(t *testing.T) {
+	notes := link.NewLink("notes", "file:///home/me/notes.txt", "", "")
+
+	tests := []struct {
+		name     string
+		opts     Options
+		status   int
+		location string
+	}{
+		// Only golink open can reach file:// links unless the server allows them
+		{"not allowed by default", Options{}, http.StatusForbidden, ""},
+		{"allowed scheme", Options{RedirectSchemes: []string{"file"}}, http.StatusFound, "file:///home/me/notes.txt"},
+	}
+	for _, tt := range tests {
+		s := newTestServer(t, tt.opts, notes)
+		w := httptest.NewRecorder()
+		s.handleRedirect(w, httptest.NewRequest(http.MethodGet, "/notes", nil))
+		if w.Code != tt.status || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s: status %d, Location %q; want %d, %q", tt.name, w.Code, w.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+}
+
+func TestHandleRedirectDottedAliases(t *testing.T) {
+	var links []*link.Link
+	for _, alias := range []string{"k8s", "k8s.dev", "v1.2", "report.pdf", "team/report.pdf"} {
+		links = append(links, link.NewLink(alias, "https://example.com/"+alias, "", ""))
+	}
+	s := newTestServer(t, Options{}, links...)
+
+	// Dots are part of the alias, not an extension to strip or a file to serve
+	for _, alias := range []string{"k8s.dev", "v1.2", "report.pdf", "team/report.pdf"} {
+		w := httptest.NewRecorder()
+		s.handleRedirect(w, httptest.NewRequest(http.MethodGet, "/"+alias, nil))
+		if want := "https://example.com/" + alias; w.Code != http.StatusFound || w.Header().Get("Location") != want {
+			t.Errorf("/%s: status %d, Location %q; want %d, %q", alias, w.Code, w.Header().Get("Location"), http.StatusFound, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// statsPath serves traffic stats; polling it isn't counted as traffic
+const statsPath = "/api/stats"
+
+// LinkHits is the number of redirects served for an alias
+type LinkHits struct {
+	Alias string `json:"alias"`
+	Hits  uint64 `json:"hits"`
+}
+
+// Stats summarizes the server's traffic since it started. Counts are kept in
+// memory and reset when the server restarts.
+type Stats struct {
+	Started        time.Time       `json:"started"`
+	Requests       uint64          `json:"requests"`
+	Redirects      uint64          `json:"redirects"`
+	TopLinks       []LinkHits      `json:"top_links"`
+	RecentNotFound []NotFoundEntry `json:"recent_not_found"`
+}
+
+// hitCounter counts requests and redirects per alias
+type hitCounter struct {
+	started  time.Time
+	requests atomic.Uint64
+	mutex    sync.Mutex
+	hits     map[string]uint64
+}
+
+// newHitCounter creates an empty counter starting now
+func newHitCounter() *hitCounter {
+	return &hitCounter{
+		started: time.Now(),
+		hits:    make(map[string]uint64),
+	}
+}
+
+// middleware counts every request except polls of the stats endpoint
+func (c *hitCounter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != statsPath {
+			c.requests.Add(1)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// record counts a redirect for alias
+func (c *hitCounter) record(alias string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.hits[alias]++
+}
+
+// top returns the total redirects and up to n aliases ordered by hits, most
+// used first
+func (c *hitCounter) top(n int) (uint64, []LinkHits) {
+	c.mutex.Lock()
+	var total uint64
+	result := make([]LinkHits, 0, len(c.hits))
+	for alias, hits := range c.hits {
+		total += hits
+		result = append(result, LinkHits{Alias: alias, Hits: hits})
+	}
+	c.mutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Hits != result[j].Hits {
+			return result[i].Hits > result[j].Hits
+		}
+		return result[i].Alias < result[j].Alias
+	})
+
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return total, result
+}
+
+// handleStats returns traffic counts since the server started as JSON
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	redirects, top := s.hits.top(limit)
+	stats := Stats{
+		Started:        s.hits.started,
+		Requests:       s.hits.requests.Load(),
+		Redirects:      redirects,
+		TopLinks:       top,
+		RecentNotFound: s.missing.recent(limit),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"math/rand/v2"
+	"sync"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// targetPicker chooses which target to redirect to, for links with several
+// targets. Round-robin positions are kept per alias in memory and start
+// over when the server restarts.
+type targetPicker struct {
+	mutex sync.Mutex
+	next  map[string]int
+}
+
+// newTargetPicker creates a picker with every alias at its first target
+func newTargetPicker() *targetPicker {
+	return &targetPicker{next: make(map[string]int)}
+}
+
+// pick returns the target URL to use for the next redirect to l. Schedules
+// are applied before it is called.
+func (p *targetPicker) pick(l *link.Link) string {
+	targets := l.URLs()
+	if len(targets) == 1 {
+		return targets[0]
+	}
+
+	if l.TargetPolicy == link.PolicyRandom {
+		return targets[rand.IntN(len(targets))]
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// The list may have shrunk since the last pick, so wrap rather than index
+	i := p.next[l.Alias] % len(targets)
+	p.next[l.Alias] = i + 1
+	return targets[i]
+}
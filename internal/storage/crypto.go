@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// encryptedMagic starts every encrypted links file, so an encrypted file can
+// be recognized without the passphrase. It is also authenticated along with
+// the links.
+var encryptedMagic = []byte("golink-encrypted-v1\n")
+
+const (
+	saltSize         = 16
+	keySize          = 32
+	pbkdf2Iterations = 600_000
+)
+
+var (
+	// ErrEncrypted is returned when an encrypted links file is read without a passphrase
+	ErrEncrypted = errors.New("links file is encrypted; set GOLINK_PASSPHRASE to open it")
+
+	// ErrWrongPassphrase is returned when an encrypted links file can't be decrypted
+	ErrWrongPassphrase = errors.New("wrong passphrase for encrypted links file (or the file is corrupted)")
+)
+
+// isEncrypted reports whether data is an encrypted links file
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// sealer encrypts and decrypts links files with a passphrase. An encrypted
+// file is the magic header, a random salt, a random nonce and the AES-GCM
+// sealed JSON, with the key derived from the passphrase and salt by PBKDF2.
+// The derived key is cached because deriving it is deliberately slow.
+type sealer struct {
+	passphrase string
+	salt       []byte
+	key        []byte
+}
+
+// deriveKey returns the key for salt, reusing the cached key when possible
+func (c *sealer) deriveKey(salt []byte) ([]byte, error) {
+	if c.key != nil && bytes.Equal(salt, c.salt) {
+		return c.key, nil
+	}
+
+	key, err := pbkdf2.Key(sha256.New, c.passphrase, salt, pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	c.salt = append([]byte(nil), salt...)
+	c.key = key
+	return key, nil
+}
+
+// open decrypts an encrypted links file
+func (c *sealer) open(data []byte) ([]byte, error) {
+	if c.passphrase == "" {
+		return nil, ErrEncrypted
+	}
+
+	rest := data[len(encryptedMagic):]
+	if len(rest) < saltSize {
+		return nil, ErrWrongPassphrase
+	}
+	key, err := c.deriveKey(rest[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	rest = rest[saltSize:]
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], encryptedMagic)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+// seal encrypts plain as a links file, keeping the salt of the file it was
+// loaded from so the key doesn't have to be derived again
+func (c *sealer) seal(plain []byte) ([]byte, error) {
+	if c.passphrase == "" {
+		return nil, errors.New("a passphrase is required to encrypt links (set GOLINK_PASSPHRASE)")
+	}
+
+	salt := c.salt
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := c.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(encryptedMagic)+saltSize+len(nonce)+len(plain)+aead.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plain, encryptedMagic), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
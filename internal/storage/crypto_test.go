@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestOpenEncrypted(t *testing.T) {
+	s := newTestStorage(t, 10)
+	s.sealer.passphrase = "correct horse"
+	if err := s.SetEncrypted(true); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+		data       []byte
+		want       error
+	}{
+		{"right passphrase", "correct horse", data, nil},
+		{"wrong passphrase", "battery staple", data, ErrWrongPassphrase},
+		{"no passphrase", "", data, ErrEncrypted},
+		{"truncated", "correct horse", data[:len(encryptedMagic)+saltSize], ErrWrongPassphrase},
+		{"tampered", "correct horse", flipLastByte(data), ErrWrongPassphrase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &sealer{passphrase: tt.passphrase}
+			plain, err := c.open(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("open = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			links, err := decodeLinks(plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(links) != 10 {
+				t.Errorf("decrypted %d links, want 10", len(links))
+			}
+		})
+	}
+}
+
+func TestNewJSONStorageWrongPassphrase(t *testing.T) {
+	s := newTestStorage(t, 10)
+	s.sealer.passphrase = "correct horse"
+	if err := s.SetEncrypted(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewJSONStorage(s.Path(), WithPassphrase("battery staple")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("NewJSONStorage with the wrong passphrase = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if _, err := NewJSONStorage(s.Path()); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("NewJSONStorage without a passphrase = %v, want %v", err, ErrEncrypted)
+	}
+	if _, err := ReadFile(s.Path()); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("ReadFile of an encrypted file = %v, want %v", err, ErrEncrypted)
+	}
+
+	// A failed open leaves the file as it was
+	reopened, err := NewJSONStorage(s.Path(), WithPassphrase("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Count(); got != 10 {
+		t.Errorf("Count = %d with the right passphrase, want 10", got)
+	}
+}
+
+// flipLastByte returns a copy of data with its last byte changed
+func flipLastByte(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	out[len(out)-1] ^= 0xff
+	return out
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index is a small summary of a links file used for shell completion, so
+// tab-completing doesn't have to parse a large catalog on every key press
+type Index struct {
+	SourceModTime time.Time `json:"source_mod_time"`
+	SourceSize    int64     `json:"source_size"`
+	Aliases       []string  `json:"aliases"`
+	Categories    []string  `json:"categories"`
+}
+
+// LoadIndex returns the index for the links file at linksPath. The cached
+// index at indexPath is used if it was built from the file's current
+// modification time and size; otherwise it is rebuilt and rewritten.
+func LoadIndex(linksPath, indexPath string) (*Index, error) {
+	info, err := os.Stat(linksPath)
+	if os.IsNotExist(err) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(indexPath); err == nil {
+		var idx Index
+		if json.Unmarshal(data, &idx) == nil && idx.SourceModTime.Equal(info.ModTime()) && idx.SourceSize == info.Size() {
+			return &idx, nil
+		}
+	}
+
+	links, err := ReadFile(linksPath)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{
+		SourceModTime: info.ModTime(),
+		SourceSize:    info.Size(),
+		Aliases:       make([]string, 0, len(links)),
+		Categories:    []string{},
+	}
+
+	categories := make(map[string]bool)
+	for alias, l := range links {
+		idx.Aliases = append(idx.Aliases, alias)
+		if l.Category != "" && !categories[strings.ToLower(l.Category)] {
+			categories[strings.ToLower(l.Category)] = true
+			idx.Categories = append(idx.Categories, l.Category)
+		}
+	}
+	sort.Strings(idx.Aliases)
+	sort.Strings(idx.Categories)
+
+	// The index is only a cache, so failing to write it isn't an error
+	if data, err := json.Marshal(idx); err == nil {
+		writeFileAtomic(indexPath, data)
+	}
+
+	return idx, nil
+}
@@ -1,11 +1,15 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,11 +18,50 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
-// JSONStorage implements link storage using a JSON file
+var (
+	// ErrNotFound is returned when an alias isn't in the storage
+	ErrNotFound = errors.New("link not found")
+
+	// ErrExists is returned when creating a link whose alias is taken
+	ErrExists = errors.New("link alias already exists")
+)
+
+// JSONStorage implements link storage using a JSON file. Stored links are
+// never modified in place: writes store copies and reloads swap in a fresh
+// map, so a link read from the storage never changes underneath its reader.
 type JSONStorage struct {
-	filePath string
-	links    map[string]*link.Link
-	mutex    sync.RWMutex
+	filePath  string
+	links     map[string]*link.Link
+	mutex     sync.RWMutex
+	sealer    sealer
+	encrypted bool
+	skipped   int  // Malformed entries skipped by the last streaming load
+	compact   bool // Save without indentation
+
+	patterns []patternLink             // Pattern links in the order they are tried
+	compiled map[string]*regexp.Regexp // Compiled patterns by source
+}
+
+// Option configures a JSONStorage
+type Option func(*JSONStorage)
+
+// WithPassphrase sets the passphrase used to open an encrypted links file and
+// to encrypt it again on save. Plaintext files stay plaintext; use
+// SetEncrypted to switch.
+func WithPassphrase(passphrase string) Option {
+	return func(s *JSONStorage) {
+		s.sealer.passphrase = passphrase
+	}
+}
+
+// WithPretty sets whether the file is saved indented, one field per line,
+// which is easy to edit and diff, or as compact JSON, which is smaller and
+// faster for large catalogs. Files are saved indented by default; both forms
+// load the same way.
+func WithPretty(pretty bool) Option {
+	return func(s *JSONStorage) {
+		s.compact = !pretty
+	}
 }
 
 // watchFile monitors the JSON file for changes and reloads when detected
@@ -44,8 +87,9 @@ func (s *JSONStorage) watchFile() {
 				return
 			}
 
-			// If our file was modified
-			if event.Name == s.filePath && (event.Op&fsnotify.Write == fsnotify.Write) {
+			// If our file was modified, or replaced by a save (which renames
+			// a new file over it)
+			if event.Name == s.filePath && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
 				// Give the file system a moment to complete the write
 				time.Sleep(100 * time.Millisecond)
 
@@ -68,7 +112,7 @@ func (s *JSONStorage) watchFile() {
 }
 
 // NewJSONStorage creates a new JSONStorage
-func NewJSONStorage(filePath string) (*JSONStorage, error) {
+func NewJSONStorage(filePath string, opts ...Option) (*JSONStorage, error) {
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
 		return nil, err
@@ -84,6 +128,9 @@ func NewJSONStorage(filePath string) (*JSONStorage, error) {
 		filePath: absPath,
 		links:    make(map[string]*link.Link),
 	}
+	for _, opt := range opts {
+		opt(storage)
+	}
 
 	// Load existing data if file exists
 	if _, err := os.Stat(absPath); !os.IsNotExist(err) {
@@ -98,6 +145,11 @@ func NewJSONStorage(filePath string) (*JSONStorage, error) {
 	return storage, nil
 }
 
+// Path returns the absolute path of the JSON file backing the storage
+func (s *JSONStorage) Path() string {
+	return s.filePath
+}
+
 // Save persists links to the JSON file (for external use)
 func (s *JSONStorage) Save() error {
 	s.mutex.Lock()
@@ -106,34 +158,72 @@ func (s *JSONStorage) Save() error {
 	return s.saveWithoutLock()
 }
 
+// Reload re-reads the JSON file and returns the number of links loaded. The
+// new links replace the old ones in a single swap under the write lock, so
+// readers see either the old or the new catalog, never a partial one; if the
+// file can't be read or parsed, or is empty while links are loaded, the
+// current links are kept.
+func (s *JSONStorage) Reload() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+	return len(s.links), nil
+}
+
 // load reads links from the JSON file
 func (s *JSONStorage) load() error {
-	data, err := os.ReadFile(s.filePath)
+	info, err := os.Stat(s.filePath)
 	if err != nil {
 		return err
 	}
 
-	// s.mutex.Lock()
-	// defer s.mutex.Unlock()
+	// Stream large plaintext files; encrypted ones are decrypted whole below
+	if info.Size() > streamThreshold {
+		links, skipped, err := streamLinks(s.filePath)
+		if !errors.Is(err, ErrEncrypted) {
+			if err != nil {
+				return err
+			}
+			s.links = links
+			s.encrypted = false
+			s.skipped = skipped
+			s.indexPatterns()
+			return nil
+		}
+	}
 
-	// return json.Unmarshal(data, &s.links)
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return err
+	}
 
-	// Create a temporary map to load the data
-	tempLinks := make(map[string]*link.Link)
+	// A file truncated by an editor or another program that is still
+	// writing it would otherwise replace every link with nothing
+	if len(data) == 0 && len(s.links) > 0 {
+		return fmt.Errorf("%s is empty; keeping the %d links already loaded", s.filePath, len(s.links))
+	}
 
-	// If the file is empty, just use an empty map
-	if len(data) == 0 {
-		s.links = tempLinks
-		return nil
+	encrypted := isEncrypted(data)
+	if encrypted {
+		if data, err = s.sealer.open(data); err != nil {
+			return err
+		}
 	}
 
-	// Unmarshal JSON into the temporary map
-	if err := json.Unmarshal(data, &tempLinks); err != nil {
+	// Decode into a fresh map so a bad file leaves the current links in place
+	links, err := decodeLinks(data)
+	if err != nil {
 		return err
 	}
 
 	// Replace the links map with our newly loaded data
-	s.links = tempLinks
+	s.links = links
+	s.encrypted = encrypted
+	s.skipped = 0
+	s.indexPatterns()
 	return nil
 }
 
@@ -143,73 +233,380 @@ func (s *JSONStorage) Create(l *link.Link) error {
 	defer s.mutex.Unlock()
 
 	if _, exists := s.links[l.Alias]; exists {
-		return errors.New("link alias already exists")
+		return ErrExists
 	}
 
-	s.links[l.Alias] = l
+	s.links[l.Alias] = l.Clone()
+	s.indexPatterns()
 	// Don't call Save() while holding the lock
 	return s.saveWithoutLock() // Call a private method that doesn't try to acquire the lock again
 }
 
 // saveWithoutLock saves without acquiring the lock (to be used internally)
 func (s *JSONStorage) saveWithoutLock() error {
-	data, err := json.MarshalIndent(s.links, "", "  ")
+	// Writing the file back would silently drop the skipped entries
+	if s.skipped > 0 {
+		return fmt.Errorf("%s has %d malformed links that were skipped when loading; fix or remove them before making changes", s.filePath, s.skipped)
+	}
+
+	data, err := encodeLinks(s.links, !s.compact)
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(s.filePath, data, 0644)
-	return err
+	if s.encrypted {
+		if data, err = s.sealer.seal(data); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(s.filePath, data)
 }
 
-// Get retrieves a link by alias
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so readers and the file watcher see the old contents or the
+// new ones, never an empty or half-written file. An existing file keeps its
+// permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Encrypted reports whether the links file is encrypted at rest
+func (s *JSONStorage) Encrypted() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.encrypted
+}
+
+// SetEncrypted switches the links file between plaintext and encrypted, and
+// rewrites it in the new form. Encrypting requires a passphrase.
+func (s *JSONStorage) SetEncrypted(encrypted bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.encrypted
+	s.encrypted = encrypted
+	if err := s.saveWithoutLock(); err != nil {
+		s.encrypted = previous
+		return err
+	}
+	return nil
+}
+
+// Get retrieves a link by alias. The link is a copy, so changing it has no
+// effect until it is passed to Update.
 func (s *JSONStorage) Get(alias string) (*link.Link, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	l, exists := s.links[alias]
 	if !exists {
-		return nil, errors.New("link not found")
+		return nil, ErrNotFound
 	}
 
-	return l, nil
+	return l.Clone(), nil
+}
+
+// Lookup returns the stored link for alias without copying it, for hot
+// paths like serving redirects. The link must not be modified; use Get for
+// a copy that can be.
+func (s *JSONStorage) Lookup(alias string) (*link.Link, bool) {
+	s.mutex.RLock()
+	l, ok := s.links[alias]
+	s.mutex.RUnlock()
+	return l, ok
+}
+
+// LookupRedirect is Lookup for following a request or an alias: reference
+// to a link's URL. Pattern links are skipped, since their alias only names
+// them; use MatchPattern.
+func (s *JSONStorage) LookupRedirect(alias string) (*link.Link, bool) {
+	l, ok := s.Lookup(alias)
+	if ok && l.Pattern != "" {
+		return nil, false
+	}
+	return l, ok
 }
 
-// List returns all links
+// List returns copies of all links
 func (s *JSONStorage) List() []*link.Link {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	result := make([]*link.Link, 0, len(s.links))
 	for _, l := range s.links {
-		result = append(result, l)
+		result = append(result, l.Clone())
 	}
 
 	return result
 }
 
+// ForEach calls fn for each link, in no particular order, until fn returns
+// false. Links are passed without copying and the read lock is held
+// throughout, so fn must not modify the link or call back into the storage.
+// The links may be kept for reading after the call; use List for copies
+// that can be changed.
+func (s *JSONStorage) ForEach(fn func(*link.Link) bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, l := range s.links {
+		if !fn(l) {
+			return
+		}
+	}
+}
+
+// Count returns the number of links
+func (s *JSONStorage) Count() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.links)
+}
+
 // Update modifies an existing link
 func (s *JSONStorage) Update(l *link.Link) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if _, exists := s.links[l.Alias]; !exists {
-		return errors.New("link not found")
+		return ErrNotFound
 	}
 
-	s.links[l.Alias] = l
+	l.Touch()
+	s.links[l.Alias] = l.Clone()
+	s.indexPatterns()
 	return s.saveWithoutLock() // Use the internal method
 }
 
+// UpdateMany modifies several existing links and saves them in one batch
+func (s *JSONStorage) UpdateMany(links []*link.Link) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Check everything first so a missing alias doesn't leave a partial update
+	for _, l := range links {
+		if _, exists := s.links[l.Alias]; !exists {
+			return fmt.Errorf("%w: %s", ErrNotFound, l.Alias)
+		}
+	}
+
+	for _, l := range links {
+		l.Touch()
+		s.links[l.Alias] = l.Clone()
+	}
+	s.indexPatterns()
+	return s.saveWithoutLock()
+}
+
 // Delete removes a link
 func (s *JSONStorage) Delete(alias string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if _, exists := s.links[alias]; !exists {
-		return errors.New("link not found")
+		return ErrNotFound
 	}
 
 	delete(s.links, alias)
+	s.indexPatterns()
+	return s.saveWithoutLock()
+}
+
+// ReadFile loads links from a file in the storage format, without creating a
+// storage or watching the file. The result is keyed by alias.
+func ReadFile(path string) (map[string]*link.Link, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeLinks(data)
+}
+
+// Entry is a single alias key and its link as it appears in a links file
+type Entry struct {
+	Key  string
+	Link *link.Link
+}
+
+// ReadEntries loads the entries of a links file in file order, keeping
+// repeated keys that ReadFile would silently collapse. It is meant for
+// validating a file rather than using it.
+func ReadEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	if isEncrypted(data) {
+		return nil, ErrEncrypted
+	}
+
+	var entries []Entry
+	err = walkEntries(bytes.NewReader(data), func(key string, raw json.RawMessage) error {
+		var l link.Link
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return fmt.Errorf("entry %q: %w", key, err)
+		}
+		entries = append(entries, Entry{Key: key, Link: &l})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// WriteFile writes links to a file in the storage format, so it can be
+// loaded as a links.json. pretty is as for WithPretty.
+func WriteFile(path string, links []*link.Link, pretty bool) error {
+	byAlias := make(map[string]*link.Link, len(links))
+	for _, l := range links {
+		byAlias[l.Alias] = l
+	}
+
+	data, err := encodeLinks(byAlias, pretty)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// encodeLinks serializes links keyed by alias in the on-disk format. Entries
+// are written in alias order from a sorted slice rather than relying on how
+// the map happens to be marshaled, so the same links always produce the same
+// bytes and the file diffs cleanly under version control. Unless pretty is
+// set, no whitespace is added.
+func encodeLinks(links map[string]*link.Link, pretty bool) ([]byte, error) {
+	aliases := make([]string, 0, len(links))
+	for alias := range links {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, alias := range aliases {
+		key, err := json.Marshal(alias)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if !pretty {
+			value, err := json.Marshal(links[alias])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(key)
+			buf.WriteString(":")
+			buf.Write(value)
+			continue
+		}
+
+		value, err := json.MarshalIndent(links[alias], "  ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n  ")
+		buf.Write(key)
+		buf.WriteString(": ")
+		buf.Write(value)
+	}
+	if pretty && len(aliases) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// decodeLinks parses links in the on-disk format. An empty file is an empty catalog.
+func decodeLinks(data []byte) (map[string]*link.Link, error) {
+	links := make(map[string]*link.Link)
+	if len(data) == 0 {
+		return links, nil
+	}
+	if isEncrypted(data) {
+		return nil, ErrEncrypted
+	}
+
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// MergeMany modifies some existing links and removes others, then saves
+// once, so a merge like dedupe's is applied entirely or not at all
+func (s *JSONStorage) MergeMany(updates []*link.Link, removed []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Check everything first so a missing alias doesn't leave a partial merge
+	for _, l := range updates {
+		if _, exists := s.links[l.Alias]; !exists {
+			return fmt.Errorf("%w: %s", ErrNotFound, l.Alias)
+		}
+	}
+	for _, alias := range removed {
+		if _, exists := s.links[alias]; !exists {
+			return fmt.Errorf("%w: %s", ErrNotFound, alias)
+		}
+	}
+
+	for _, l := range updates {
+		l.Touch()
+		s.links[l.Alias] = l.Clone()
+	}
+	for _, alias := range removed {
+		delete(s.links, alias)
+	}
+	s.indexPatterns()
+	return s.saveWithoutLock()
+}
+
+// DeleteMany removes several links and saves once
+func (s *JSONStorage) DeleteMany(aliases []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Check everything first so a missing alias doesn't leave a partial delete
+	for _, alias := range aliases {
+		if _, exists := s.links[alias]; !exists {
+			return fmt.Errorf("%w: %s", ErrNotFound, alias)
+		}
+	}
+
+	for _, alias := range aliases {
+		delete(s.links, alias)
+	}
+	s.indexPatterns()
 	return s.saveWithoutLock()
 }
@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// testLinks returns n links with distinct aliases, URLs and categories
+func testLinks(n int) []*link.Link {
+	links := make([]*link.Link, n)
+	for i := range links {
+		links[i] = link.NewLink(
+			fmt.Sprintf("link-%06d", i),
+			fmt.Sprintf("https://example.com/docs/%d", i),
+			fmt.Sprintf("Link number %d", i),
+			fmt.Sprintf("category-%d", i%20),
+		)
+	}
+	return links
+}
+
+// newTestStorage creates a storage backed by a file in a temporary
+// directory, holding n links
+func newTestStorage(tb testing.TB, n int) *JSONStorage {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "links.json")
+	if err := WriteFile(path, testLinks(n), true); err != nil {
+		tb.Fatal(err)
+	}
+	s, err := NewJSONStorage(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+func TestEncodeLinksStable(t *testing.T) {
+	links := make(map[string]*link.Link)
+	for _, l := range testLinks(50) {
+		links[l.Alias] = l
+	}
+	links["link-000007"].Targets = []string{"https://a.example.com", "https://b.example.com"}
+	links["link-000011"].Schedule = []link.ScheduleRule{{URL: "https://c.example.com", Days: []string{"mon"}, From: "09:00", Until: "10:00"}}
+
+	for _, pretty := range []bool{true, false} {
+		t.Run(fmt.Sprintf("pretty=%v", pretty), func(t *testing.T) {
+			first, err := encodeLinks(links, pretty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := encodeLinks(links, pretty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first, second) {
+				t.Fatal("encoding the same links twice gave different bytes")
+			}
+
+			// Saving again after a reload must not change the file either
+			path := filepath.Join(t.TempDir(), "links.json")
+			if err := os.WriteFile(path, first, 0644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := NewJSONStorage(path, WithPretty(pretty))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Save(); err != nil {
+				t.Fatal(err)
+			}
+			saved, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(first, saved) {
+				t.Errorf("file changed after reload and save:\n%s\nwant:\n%s", saved, first)
+			}
+		})
+	}
+}
+
+// TestReloadConcurrentReads reads links while the file is reloaded with a
+// different catalog. Run it with -race: readers must only ever see a whole
+// old or new catalog, and links they hold must not change underneath them.
+func TestReloadConcurrentReads(t *testing.T) {
+	const n = 200
+	s := newTestStorage(t, n)
+
+	// The same aliases with other URLs, so a reload swaps every link
+	other := testLinks(n)
+	for _, l := range other {
+		l.URL += "?v=2"
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if got := len(s.List()); got != n {
+					t.Errorf("List returned %d links during reload, want %d", got, n)
+					return
+				}
+				if got := s.Count(); got != n {
+					t.Errorf("Count = %d during reload, want %d", got, n)
+					return
+				}
+				l, err := s.Get("link-000042")
+				if err != nil {
+					t.Errorf("Get during reload: %v", err)
+					return
+				}
+				c := l.Clone()
+				c.Description = "changed by a reader"
+				if held, ok := s.Lookup("link-000007"); ok {
+					_ = held.URL + held.Description
+				}
+				s.ForEach(func(l *link.Link) bool {
+					_ = l.URL
+					return true
+				})
+			}
+		}()
+	}
+
+	for i := range 50 {
+		links := testLinks(n)
+		if i%2 == 0 {
+			links = other
+		}
+		if err := WriteFile(s.Path(), links, true); err != nil {
+			t.Fatal(err)
+		}
+		if count, err := s.Reload(); err != nil || count != n {
+			t.Fatalf("Reload = %d, %v; want %d links", count, err, n)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestReloadEmptyFileKeepsLinks(t *testing.T) {
+	s := newTestStorage(t, 10)
+
+	// As the file looks while another program is rewriting it in place
+	if err := os.WriteFile(s.Path(), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reload(); err == nil {
+		t.Error("Reload of an empty file succeeded, want an error")
+	}
+	if got := s.Count(); got != 10 {
+		t.Errorf("Count = %d after reloading an empty file, want the 10 links kept", got)
+	}
+}
+
+func TestSaveReplacesFile(t *testing.T) {
+	s := newTestStorage(t, 10)
+	if err := os.Chmod(s.Path(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode after save = %v, want the file's 0600 kept", info.Mode().Perm())
+	}
+	// No temporary files are left next to it
+	if entries, _ := os.ReadDir(filepath.Dir(s.Path())); len(entries) != 1 {
+		t.Errorf("directory holds %d files after save, want only the links file", len(entries))
+	}
+}
+
+// benchmarkSizes are the catalog sizes the storage benchmarks run at
+var benchmarkSizes = []int{100, 10_000}
+
+func BenchmarkList(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		s := newTestStorage(b, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if got := len(s.List()); got != n {
+					b.Fatalf("List returned %d links, want %d", got, n)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkForEach walks the same catalogs as BenchmarkList without copying
+// them, as the homepage and category counts do
+func BenchmarkForEach(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		s := newTestStorage(b, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				count := 0
+				s.ForEach(func(*link.Link) bool {
+					count++
+					return true
+				})
+				if count != n {
+					b.Fatalf("ForEach visited %d links, want %d", count, n)
+				}
+			}
+		})
+	}
+}
+
+func TestDottedAliasesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	aliases := []string{"k8s.dev", "v1.2", "report.pdf", "team/report.pdf"}
+	var links []*link.Link
+	for _, alias := range aliases {
+		links = append(links, link.NewLink(alias, "https://example.com/"+alias, "", ""))
+	}
+	if err := WriteFile(path, links, true); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewJSONStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range aliases {
+		l, err := s.Get(alias)
+		if err != nil || l.Alias != alias {
+			t.Errorf("Get(%q) after reload = %v, %v", alias, l, err)
+		}
+	}
+}
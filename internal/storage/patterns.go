@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// patternLink is a pattern link with its compiled pattern
+type patternLink struct {
+	link *link.Link
+	re   *regexp.Regexp
+}
+
+// indexPatterns rebuilds the list of pattern links after the links change,
+// in creation order. Compiled patterns are reused while their source is
+// unchanged. The caller must hold the write lock.
+func (s *JSONStorage) indexPatterns() {
+	compiled := make(map[string]*regexp.Regexp)
+	var patterns []patternLink
+	for _, l := range s.links {
+		if l.Pattern == "" {
+			continue
+		}
+
+		re, ok := s.compiled[l.Pattern]
+		if !ok {
+			var err error
+			if re, err = link.CompilePattern(l.Pattern); err != nil {
+				// Checked on creation, so only a hand-edited file gets here
+				log.Printf("Skipping pattern link %s: %v", l.Alias, err)
+				continue
+			}
+		}
+		compiled[l.Pattern] = re
+		patterns = append(patterns, patternLink{link: l, re: re})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		a, b := patterns[i].link, patterns[j].link
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.Alias < b.Alias
+	})
+	s.patterns, s.compiled = patterns, compiled
+}
+
+// MatchPattern tries the pattern links in creation order against alias and
+// returns the first that matches, with its URL filled from the captured
+// groups. Like Lookup, the link is not copied and must not be modified.
+func (s *JSONStorage) MatchPattern(alias string) (*link.Link, string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, p := range s.patterns {
+		if target, ok := link.ExpandPattern(p.re, alias, p.link.URL); ok {
+			return p.link, target, true
+		}
+	}
+	return nil, "", false
+}
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// Search scores, one per kind of match. A link gets the score of its best
+// match, so results rank: exact alias, alias prefix, alias substring, a
+// substring of another field, then fuzzy alias matches.
+const (
+	ScoreExact     = 1000 // The alias is the query
+	ScorePrefix    = 800  // The alias starts with the query
+	ScoreSubstring = 600  // The alias contains the query
+	ScoreField     = 400  // The description, category or URL contains the query
+	ScoreFuzzy     = 200  // The query's characters appear in order in the alias
+)
+
+// SearchResult is a link matching a search query
+type SearchResult struct {
+	Link    *link.Link `json:"link"`
+	Score   int        `json:"score"`
+	Matched []string   `json:"matched"` // Fields the query was found in: alias, description, category, url
+
+	// Alias and description as HTML with the matching characters in <mark>
+	// tags, filled in by Highlight
+	Highlights map[string]string `json:"highlights,omitempty"`
+
+	aliasSpans       [][2]int
+	descriptionSpans [][2]int
+}
+
+// Search returns up to limit links matching query, case-insensitively, best
+// first. Within a score, shorter aliases rank first, then aliases in
+// alphabetical order, so results are deterministic. An empty query matches
+// nothing.
+func (s *JSONStorage) Search(query string, limit int) []*SearchResult {
+	query = strings.TrimSpace(query)
+	if query == "" || limit <= 0 {
+		return nil
+	}
+
+	var results []*SearchResult
+	s.ForEach(func(l *link.Link) bool {
+		if r := matchLink(l, query); r != nil {
+			results = append(results, r)
+		}
+		return true
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Link.Alias) != len(b.Link.Alias) {
+			return len(a.Link.Alias) < len(b.Link.Alias)
+		}
+		return a.Link.Alias < b.Link.Alias
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	// Results hold copies, so callers can't modify stored links
+	for _, r := range results {
+		r.Link = r.Link.Clone()
+	}
+	return results
+}
+
+// matchLink scores l against query, or returns nil if it doesn't match
+func matchLink(l *link.Link, query string) *SearchResult {
+	r := &SearchResult{Link: l}
+
+	if span, ok := indexFold(l.Alias, query); ok {
+		switch {
+		case span[0] == 0 && span[1] == len(l.Alias):
+			r.Score = ScoreExact
+		case span[0] == 0:
+			r.Score = ScorePrefix
+		default:
+			r.Score = ScoreSubstring
+		}
+		r.Matched = append(r.Matched, "alias")
+		r.aliasSpans = [][2]int{span}
+	}
+
+	if span, ok := indexFold(l.Description, query); ok {
+		r.Matched = append(r.Matched, "description")
+		r.descriptionSpans = [][2]int{span}
+	}
+	if _, ok := indexFold(l.Category, query); ok {
+		r.Matched = append(r.Matched, "category")
+	}
+	for _, u := range l.URLs() {
+		if _, ok := indexFold(u, query); ok {
+			r.Matched = append(r.Matched, "url")
+			break
+		}
+	}
+	if r.Score == 0 && len(r.Matched) > 0 {
+		r.Score = ScoreField
+	}
+
+	if r.Score == 0 {
+		spans := fuzzySpans(l.Alias, query)
+		if spans == nil {
+			return nil
+		}
+		r.Score = ScoreFuzzy
+		r.Matched = []string{"alias"}
+		r.aliasSpans = spans
+	}
+	return r
+}
+
+// indexFold returns the byte span of the first case-insensitive match of
+// query in s. Spans are found in s itself rather than a lowercased copy,
+// whose byte offsets can differ.
+func indexFold(s, query string) ([2]int, bool) {
+	n := utf8.RuneCountInString(query)
+	for i := range s {
+		j := i
+		for k := 0; k < n && j < len(s); k++ {
+			_, size := utf8.DecodeRuneInString(s[j:])
+			j += size
+		}
+		if strings.EqualFold(s[i:j], query) {
+			return [2]int{i, j}, true
+		}
+	}
+	return [2]int{}, false
+}
+
+// fuzzySpans returns the byte spans of query's characters found in order in
+// s, case-insensitively and merging adjacent ones, or nil if they aren't
+// all there
+func fuzzySpans(s, query string) [][2]int {
+	var spans [][2]int
+	pos := 0
+	for _, q := range query {
+		found := false
+		for i, c := range s[pos:] {
+			if !strings.EqualFold(string(c), string(q)) {
+				continue
+			}
+			at, end := pos+i, pos+i+utf8.RuneLen(c)
+			if n := len(spans); n > 0 && spans[n-1][1] == at {
+				spans[n-1][1] = end
+			} else {
+				spans = append(spans, [2]int{at, end})
+			}
+			pos, found = end, true
+			break
+		}
+		if !found {
+			return nil
+		}
+	}
+	return spans
+}
+
+// snippetRadius is how much description text Highlight keeps either side
+// of a match
+const snippetRadius = 40
+
+// Highlight fills in r.Highlights. Long descriptions are cut down to the
+// text around the match.
+func (r *SearchResult) Highlight() {
+	r.Highlights = map[string]string{}
+	if r.aliasSpans != nil {
+		r.Highlights["alias"] = markSpans(r.Link.Alias, r.aliasSpans)
+	}
+	if r.descriptionSpans != nil {
+		text, span := r.Link.Description, r.descriptionSpans[0]
+		start, end := max(span[0]-snippetRadius, 0), min(span[1]+snippetRadius, len(text))
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		snippet := markSpans(text[start:end], [][2]int{{span[0] - start, span[1] - start}})
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+		if end < len(text) {
+			snippet += "…"
+		}
+		r.Highlights["description"] = snippet
+	}
+}
+
+// markSpans escapes text for HTML, wrapping each span in <mark>
+func markSpans(text string, spans [][2]int) string {
+	var b strings.Builder
+	prev := 0
+	for _, span := range spans {
+		b.WriteString(html.EscapeString(text[prev:span[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[span[0]:span[1]]) + "</mark>")
+		prev = span[1]
+	}
+	b.WriteString(html.EscapeString(text[prev:]))
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+func TestSearchRanking(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	links := []*link.Link{
+		link.NewLink("docs", "https://example.com/docs", "", ""),
+		link.NewLink("docs-api", "https://example.com/api", "", ""),
+		link.NewLink("docsite", "https://example.com/site", "", ""),
+		link.NewLink("go-docs", "https://go.dev/doc", "", ""),
+		link.NewLink("wiki", "https://example.com/wiki", "Team docs and notes", ""),
+		link.NewLink("handbook", "https://example.com/docs/handbook", "", ""),
+		link.NewLink("dropcaps", "https://fonts.example.com", "", ""),
+		link.NewLink("calendar", "https://calendar.example.com", "", ""),
+	}
+	if err := WriteFile(path, links, false); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewJSONStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		// Exact, prefix (shorter first), substring, other fields, then fuzzy
+		{"docs", 10, []string{"docs", "docsite", "docs-api", "go-docs", "wiki", "handbook", "dropcaps"}},
+		{"DOCS", 10, []string{"docs", "docsite", "docs-api", "go-docs", "wiki", "handbook", "dropcaps"}},
+		{"docs", 3, []string{"docs", "docsite", "docs-api"}},
+		{"  wiki ", 10, []string{"wiki"}},
+		{"notes", 10, []string{"wiki"}},
+		{"cldr", 10, []string{"calendar"}},
+		{"nothing-matches", 10, nil},
+		{"", 10, nil},
+		{"docs", 0, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range s.Search(tt.query, tt.limit) {
+			got = append(got, r.Link.Alias)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSearchScores(t *testing.T) {
+	tests := []struct {
+		alias, description, query string
+		wantScore                 int
+		wantMatched               []string
+	}{
+		{"docs", "", "docs", ScoreExact, []string{"alias"}},
+		{"docsite", "", "docs", ScorePrefix, []string{"alias"}},
+		{"go-docs", "Go docs", "docs", ScoreSubstring, []string{"alias", "description"}},
+		{"wiki", "Team docs", "docs", ScoreField, []string{"description"}},
+		{"dropcaps", "", "docs", ScoreFuzzy, []string{"alias"}},
+	}
+	for _, tt := range tests {
+		l := link.NewLink(tt.alias, "https://example.com", tt.description, "")
+		r := matchLink(l, tt.query)
+		if r == nil {
+			t.Errorf("matchLink(%q, %q) = nil, want a match", tt.alias, tt.query)
+			continue
+		}
+		if r.Score != tt.wantScore || !slices.Equal(r.Matched, tt.wantMatched) {
+			t.Errorf("matchLink(%q, %q) = score %d matched %v, want score %d matched %v",
+				tt.alias, tt.query, r.Score, r.Matched, tt.wantScore, tt.wantMatched)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// streamThreshold is the file size above which links are decoded one entry
+// at a time, so a large catalog isn't held in memory twice and a malformed
+// entry is skipped instead of failing the whole load
+const streamThreshold = 16 << 20
+
+// walkEntries decodes a links file object one entry at a time, calling fn
+// with each alias and its raw JSON. Syntax errors stop the walk since the
+// rest of the file can't be located reliably.
+func walkEntries(r io.Reader, fn func(key string, raw json.RawMessage) error) error {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("links file must contain a JSON object keyed by alias")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("entry %q: %w", key, err)
+		}
+		if err := fn(key, raw); err != nil {
+			return err
+		}
+	}
+
+	_, err := dec.Token()
+	return err
+}
+
+// streamLinks reads a plaintext links file entry by entry. Entries that are
+// valid JSON but not a valid link are logged and skipped; the number skipped
+// is returned with the links. Encrypted files return ErrEncrypted since they
+// have to be decrypted whole.
+func streamLinks(path string) (map[string]*link.Link, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if header, _ := r.Peek(len(encryptedMagic)); isEncrypted(header) {
+		return nil, 0, ErrEncrypted
+	}
+
+	links := make(map[string]*link.Link)
+	skipped := 0
+	err = walkEntries(r, func(key string, raw json.RawMessage) error {
+		var l link.Link
+		if err := json.Unmarshal(raw, &l); err != nil {
+			log.Printf("Skipping malformed link %q in %s: %v", key, path, err)
+			skipped++
+			return nil
+		}
+		links[key] = &l
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if skipped > 0 {
+		log.Printf("Loaded %d links from %s, skipped %d malformed entries", len(links), path, skipped)
+	}
+	return links, skipped, nil
+}
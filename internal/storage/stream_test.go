@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStreamLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantAliases []string
+		wantSkipped int
+		wantErr     bool
+	}{
+		{
+			name:        "valid",
+			data:        `{"docs": {"url": "https://example.com/docs"}, "wiki": {"url": "https://example.com/wiki"}}`,
+			wantAliases: []string{"docs", "wiki"},
+		},
+		{
+			name:        "empty file",
+			data:        "",
+			wantAliases: nil,
+		},
+		{
+			name:        "malformed entries skipped",
+			data:        `{"docs": {"url": "https://example.com/docs"}, "bad": 42, "worse": {"url": ["x"]}, "wiki": {"url": "https://example.com/wiki"}}`,
+			wantAliases: []string{"docs", "wiki"},
+			wantSkipped: 2,
+		},
+		{
+			name:    "syntax error",
+			data:    `{"docs": {"url": "https://example.com/docs"}, "bad": {`,
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			data:    `[{"url": "https://example.com/docs"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "links.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			links, skipped, err := streamLinks(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("streamLinks error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var aliases []string
+			for alias := range links {
+				aliases = append(aliases, alias)
+			}
+			slices.Sort(aliases)
+			if !slices.Equal(aliases, tt.wantAliases) || skipped != tt.wantSkipped {
+				t.Errorf("streamLinks = %v, %d skipped, want %v, %d skipped", aliases, skipped, tt.wantAliases, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestStreamLinksEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	if err := os.WriteFile(path, append(encryptedMagic, "ciphertext"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := streamLinks(path); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("streamLinks of an encrypted file = %v, want %v", err, ErrEncrypted)
+	}
+}
+
+func TestLargeFileSkipsMalformedAndRefusesSave(t *testing.T) {
+	// Padding after the object pushes the file over the streaming threshold
+	data := []byte(`{"docs": {"url": "https://example.com/docs"}, "bad": 42}`)
+	data = append(data, bytes.Repeat([]byte(" "), streamThreshold)...)
+	path := filepath.Join(t.TempDir(), "links.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewJSONStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("docs"); err != nil {
+		t.Errorf("Get(docs) = %v, want the valid entry loaded", err)
+	}
+	if got := s.Count(); got != 1 {
+		t.Errorf("Count = %d, want 1", got)
+	}
+
+	// Saving would drop the skipped entry, so changes are refused
+	if err := s.Delete("docs"); err == nil {
+		t.Error("Delete succeeded with skipped entries, want an error")
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, data) {
+		t.Error("links file was rewritten despite skipped entries")
+	}
+}
@@ -0,0 +1,121 @@
+package tree
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bkarpinos/golink/internal/link"
+)
+
+// Node is a category, alias group or link in the display tree
+type Node struct {
+	Name     string
+	Link     *link.Link // Set only for leaves
+	Children []*Node
+}
+
+// Build groups links by category and, when separator is non-empty, by the
+// alias segments before the last separator. Categories are lowercased and
+// links without one are grouped under "uncategorized". Grouping is for
+// display only; leaves keep the full alias as their name.
+func Build(links []*link.Link, separator string) []*Node {
+	b := NewBuilder(separator)
+	for _, l := range links {
+		b.Add(l)
+	}
+	return b.Roots()
+}
+
+// Builder builds the same tree as Build one link at a time, for callers
+// that iterate links rather than hold them in a slice
+type Builder struct {
+	separator  string
+	categories map[string]*Node
+}
+
+// NewBuilder creates an empty tree grouping aliases by separator
+func NewBuilder(separator string) *Builder {
+	return &Builder{
+		separator:  separator,
+		categories: make(map[string]*Node),
+	}
+}
+
+// Add places a link in the tree
+func (b *Builder) Add(l *link.Link) {
+	cat := l.Category
+	if cat == "" {
+		cat = "uncategorized"
+	} else {
+		cat = strings.ToLower(cat) // Ensure lowercase categories
+	}
+
+	parent, ok := b.categories[cat]
+	if !ok {
+		parent = &Node{Name: cat}
+		b.categories[cat] = parent
+	}
+
+	if b.separator != "" {
+		segments := strings.Split(l.Alias, b.separator)
+		for _, segment := range segments[:len(segments)-1] {
+			parent = parent.group(segment)
+		}
+	}
+	parent.Children = append(parent.Children, &Node{Name: l.Alias, Link: l})
+}
+
+// Roots returns the category nodes, sorted by name with their children
+// sorted recursively
+func (b *Builder) Roots() []*Node {
+	roots := make([]*Node, 0, len(b.categories))
+	for _, n := range b.categories {
+		n.sort()
+		roots = append(roots, n)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Name < roots[j].Name
+	})
+
+	return roots
+}
+
+// Walk calls fn for every node in depth-first order with the box-drawing
+// prefix ("├── ", "│   └── ", ...) that places it in the tree
+func Walk(nodes []*Node, fn func(prefix string, n *Node)) {
+	walk(nodes, "", fn)
+}
+
+func walk(nodes []*Node, indent string, fn func(prefix string, n *Node)) {
+	for i, n := range nodes {
+		if i == len(nodes)-1 {
+			fn(indent+"└── ", n)
+			walk(n.Children, indent+"    ", fn)
+		} else {
+			fn(indent+"├── ", n)
+			walk(n.Children, indent+"│   ", fn)
+		}
+	}
+}
+
+// group returns the child group with the given name, creating it if needed
+func (n *Node) group(name string) *Node {
+	for _, c := range n.Children {
+		if c.Link == nil && c.Name == name {
+			return c
+		}
+	}
+	g := &Node{Name: name}
+	n.Children = append(n.Children, g)
+	return g
+}
+
+// sort orders children by name, recursively
+func (n *Node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Name < n.Children[j].Name
+	})
+	for _, c := range n.Children {
+		c.sort()
+	}
+}